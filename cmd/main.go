@@ -16,22 +16,72 @@ const (
 
 func main() {
 
-	c := celo.New()
+	// link-proxy and swap-validator work on two nodes, they are handled before bpm loads a single one
+	if len(os.Args) > 1 && os.Args[1] == "link-proxy" {
+		if len(os.Args) < 4 {
+			log.Fatalf("Usage: %s link-proxy <proxy-node.json> <validator-node.json> [external-ip]\n", os.Args[0])
+		}
+		externalIP := ""
+		if len(os.Args) > 4 {
+			externalIP = os.Args[4]
+		}
+		if err := celo.LinkProxy(os.Args[2], os.Args[3], externalIP); err != nil {
+			log.Fatalf("Unable to link proxy: %s\n", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "swap-validator" {
+		if len(os.Args) < 5 {
+			log.Fatalf("Usage: %s swap-validator <primary-node.json> <replica-node.json> <block|+blocks>\n", os.Args[0])
+		}
+		if err := celo.SwapValidator(os.Args[2], os.Args[3], os.Args[4]); err != nil {
+			log.Fatalf("Unable to schedule validator swap: %s\n", err)
+		}
+		return
+	}
+
+	c, err := celo.New()
+	if err != nil {
+		log.Fatalf("Unable to setup celo: %s\n", err)
+	}
+
+	cmd := os.Args[1]
+
+	if cmd == "create-configurations" || cmd == "start" {
+		if err := c.CheckSubtype(); err != nil {
+			log.Fatalf("%s\n", err)
+		}
+	}
+
+	if cmd == "create-configurations" {
+		if err := c.Validate(); err != nil {
+			log.Fatalf("%s\n", err)
+		}
+		if err := c.PreConfigure(); err != nil {
+			log.Fatalf("Unable to configure %s: %s\n", c.Subtype, err)
+		}
+	}
 
 	parameters := c.GetParameters()
 	containers := c.GetContainers()
 	templates := c.GetTemplates()
 
+	tests := c.Tests()
 	celoPlugin := plugin.NewDockerPlugin("celo", version, description, parameters, templates, containers)
-	celoPlugin.Tester = tester.CeloTester{}
+	celoPlugin.Tester = tester.CeloTester{Container: tests.Container, RPC: tests.RPC, AttestationPort: tests.AttestationPort}
 
-	if c.Subtype != "attestation-service" {
-		cmd := os.Args[1]
-		if cmd == "start" {
-			log.Println("Initialize genesis...")
-			_, _ = c.InitGenesis() // TODO handle erros, ffs (palmface)
+	if cmd == "start" {
+		if err := c.PreStart(); err != nil {
+			log.Fatalf("Unable to start %s: %s\n", c.Subtype, err)
 		}
 	}
 
 	plugin.Initialize(celoPlugin)
+
+	if cmd == "start" {
+		if err := c.PostStart(); err != nil {
+			log.Fatalf("Unable to start %s: %s\n", c.Subtype, err)
+		}
+	}
 }
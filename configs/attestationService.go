@@ -1,19 +1,32 @@
 package configs
 
-// AttesetationServiceEnvs Get envvars as string for env file.
+// AttesetationServiceEnvs Get envvars as string for env file, values go through `env` to reject line breaks.
 const (
-	AttesetationServiceEnvs = `ATTESTATION_SIGNER_ADDRESS={{ .Node.StrParameters.signer }}
-CELO_VALIDATOR_ADDRESS={{ .Node.StrParameters.validator }}
-CELO_PROVIDER={{ .Node.StrParameters.node_url }}
-DATABASE_URL={{ .Node.StrParameters.db_host }}
-SMS_PROVIDERS=twilio
-TWILIO_MESSAGING_SERVICE_SID={{ .Node.StrParameters.twilio_service_sid }}
-TWILIO_ACCOUNT_SID={{ .Node.StrParameters.twilio_account_sid }}
-TWILIO_BLACKLIST={{ .Node.StrParameters.twilio_blacklist }}
-TWILIO_AUTH_TOKEN={{ .Node.StrParameters.twilio_auth_token }}
-PORT={{ .Node.StrParameters.port }}`
+	AttesetationServiceEnvs = `ATTESTATION_SIGNER_ADDRESS={{ env .Config.Signer }}
+CELO_VALIDATOR_ADDRESS={{ env .Config.Validator }}
+CELO_PROVIDER={{ env .Config.NodeURL }}
+DATABASE_URL={{ env .DatabaseURL }}
+SMS_PROVIDERS={{ env .SMS.Providers }}
+{{- range .SMS.Countries }}
+SMS_PROVIDERS_{{ .Code }}={{ env .Providers }}
+{{- end }}
+{{- if .SMS.Twilio }}
+TWILIO_MESSAGING_SERVICE_SID={{ env .Config.TwilioServiceSID }}
+TWILIO_ACCOUNT_SID={{ env .Config.TwilioAccountSID }}
+TWILIO_BLACKLIST={{ env .Config.TwilioBlacklist }}
+TWILIO_AUTH_TOKEN={{ env .Config.TwilioAuthToken }}
+{{- end }}
+{{- if .SMS.Nexmo }}
+NEXMO_KEY={{ env .Config.NexmoKey }}
+NEXMO_SECRET={{ env .Config.NexmoSecret }}
+NEXMO_BLACKLIST={{ env .Config.NexmoBlacklist }}
+{{- end }}
+{{- if .SMS.MessageBird }}
+MESSAGEBIRD_API_KEY={{ env .Config.MessageBirdKey }}
+{{- end }}
+PORT={{ env .AttestationPort }}`
 
-	PostgresEnvs = `POSTGRES_PASSWORD={{ .Node.StrParameters.db_password }}
-POSTGRES_USER={{ .Node.StrParameters.db_user }}
+	PostgresEnvs = `POSTGRES_PASSWORD={{ env .Config.DBPassword }}
+POSTGRES_USER={{ env .Config.DBUser }}
 POSTGRES_DATABASE=attestation-service`
 )
@@ -3,73 +3,87 @@ package configs
 const (
 	// CollectorEnvTpl tempalte for collector command
 	CollectorEnvTpl = `SERVICE_PORT=8545
-SERVICE_HOST=bpm-{{ .Node.ID }}-{{ .Node.StrParameters.subtype }}`
+SERVICE_HOST=bpm-{{ .Node.ID }}-{{ .Config.Subtype }}`
 
-	// ProxyCmdTpl the celo command for running proxies
-	ProxyCmdTpl = `--verbosity=3
---networkid={{ .Node.StrParameters.networkid }}
---syncmode=full
+	// ProxyCmdTpl the celo command for running proxies, node settings are in config.toml
+	ProxyCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
 --proxy.proxy
---proxy.proxiedvalidatoraddress={{ .Node.StrParameters.signer }}
+--proxy.proxiedvalidatoraddress={{ .Config.Signer }}
 --proxy.internalendpoint=:30503
---rpc
---rpcvhosts=bpm-{{ .Node.ID }}-{{ .Node.StrParameters.subtype }}
---rpcaddr={{ .Node.StrParameters.rpcaddr }}
---rpcapi=eth,net,web3,debug,admin,personal
---etherbase={{ .Node.StrParameters.signer }}
---bootnodes={{ .Node.StrParameters.bootnodes }}
+--etherbase={{ .Config.Signer }}
 `
 
-	// ValidatorCmdTpl the celo command for running validator
-	ValidatorCmdTpl = `--verbosity=3
---networkid={{ .Node.StrParameters.networkid }}
---syncmode=full
+	// ValidatorCmdTpl the celo command for running validator, node settings are in config.toml
+	ValidatorCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
 --mine
---port={{ .Node.StrParameters.port }}
 --istanbul.blockperiod=5
 --istanbul.requesttimeout=3000
---etherbase={{ .Node.StrParameters.signer }}
---nodiscover
+--etherbase={{ .Config.Signer }}
 --proxy.proxied
---proxy.proxyenodeurlpair=enode://{{ .Node.StrParameters.enode }}@{{ .Node.StrParameters.proxy_internal }}:30503;enode://{{ .Node.StrParameters.enode }}@{{ .Node.StrParameters.proxy_external }}:30303
---unlock={{ .Node.StrParameters.signer }}
---rpcvhosts=bpm-{{ .Node.ID }}-{{ .Node.StrParameters.subtype }}
+--proxy.proxyenodeurlpairs={{ .ProxyEnodeURLPairs }}
+--unlock={{ .Config.Signer }}
 --password=/root/.celo/configs/.password.secret
---keystore=/root/.celo/configs/keystore
+{{ if .Config.Replica }}--istanbul.replica{{ end }}
 `
 
-	// FullnodeCmdTpl the celo command for running fullnode
-	FullnodeCmdTpl = `--verbosity=3
---networkid={{ .Node.StrParameters.networkid }}
---syncmode=full
---rpc
---rpcaddr={{ .Node.StrParameters.rpcaddr }}
---rpcapi=eth,net,web3,debug,admin,personal
---light.serve={{ .Node.StrParameters.light_serve }}
---light.maxpeers={{ .Node.StrParameters.light_maxpeers }}
---maxpeers={{ .Node.StrParameters.maxpeers }}
---port={{ .Node.StrParameters.port }}
---rpcvhosts=bpm-{{ .Node.ID }}-{{ .Node.StrParameters.subtype }}
---etherbase={{ .Node.StrParameters.account }}
---bootnodes={{ .Node.StrParameters.bootnodes }}
-{{ if eq .Node.StrParameters.nousb "true" "TRUE" "True" }}--nousb{{ end }}
---verbosity=5
+	// FullnodeCmdTpl the celo command for running fullnode, node settings are in config.toml
+	FullnodeCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
+--etherbase={{ .Config.Account }}
 `
 
-	// AttestationCmdTpl the celo command for running attestation node
-	AttestationCmdTpl = `--verbosity=3
---networkid={{ .Node.StrParameters.networkid }}
---syncmode=full
---rpc
---rpcvhosts=bpm-{{ .Node.ID }}-{{ .Node.StrParameters.subtype }}
---rpcaddr={{ .Node.StrParameters.rpcaddr }}
---rpcapi=eth,net,web3,debug,admin,personal
+	// ArchiveCmdTpl the celo command for running archive node, node settings are in config.toml
+	ArchiveCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
+`
+
+	// LightCmdTpl the celo command for running light client, node settings are in config.toml
+	LightCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
+`
+
+	// BootnodeCmdTpl the command for running a bootnode, the tools image has no entrypoint
+	BootnodeCmdTpl = `bootnode
+-nodekey=/root/.celo/nodekey
+-addr=:30301
+-verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}-vmodule={{ .Config.Vmodule }}{{ end }}
+{{ if .Config.ExternalIP }}-nat=extip:{{ .Config.ExternalIP }}{{ end }}
+`
+
+	// AttestationCmdTpl the celo command for running attestation node, node settings are in config.toml
+	AttestationCmdTpl = `--config=/root/config.toml
+--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
 --allow-insecure-unlock
---unlock={{ .Node.StrParameters.signer }}
---keystore=/root/.celo/configs/keystore
+--unlock={{ .Config.Signer }}
 --password=/root/.celo/configs/.password.secret
---bootnodes={{ .Node.StrParameters.bootnodes }}
 --bootnodesv4=enode://f65013f1ac6827e275c2d2737ce13357f620d4364124d02227a19321c57f8fbf9214a9411de49d49f180b085b031d9d23211a6ead4499fc5f9d3592b55322123@50.17.60.161:30303
+`
+
+	// DevnetValidatorCmdTpl the celo command for running a validator of a private devnet
+	DevnetValidatorCmdTpl = `--verbosity={{ .Config.Verbosity }}
+{{ if .Config.Vmodule }}--vmodule={{ .Config.Vmodule }}{{ end }}
+--networkid={{ .Config.NetworkID }}
+--syncmode=full
+--mine
+--etherbase={{ .Validator.Address }}
+--unlock={{ .Validator.Address }}
+--password=/root/.celo/.password.secret
+--allow-insecure-unlock
+--nodiscover
+--nousb
+--rpc
+--rpcaddr=0.0.0.0
+--rpcapi=eth,net,web3,debug,admin,personal,istanbul
+--rpcvhosts=bpm-{{ .Node.ID }}-devnet-validator-{{ .ValidatorIndex }}
 `
 
 	// AttestationServiceCmdTpl the celo command for running attestation service
@@ -3,8 +3,12 @@ module go.blockdaemon.com/bpm/celo
 go 1.17
 
 require (
+	github.com/BurntSushi/toml v0.3.1
 	github.com/docker/docker v20.10.14+incompatible
+	github.com/docker/go-connections v0.4.0
 	go.blockdaemon.com/bpm/sdk v0.14.0
+	golang.org/x/crypto v0.0.0-20210817164053-32db794688a5
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -12,7 +16,6 @@ require (
 	github.com/containerd/containerd v1.6.2 // indirect
 	github.com/coreos/go-semver v0.3.0 // indirect
 	github.com/docker/distribution v2.7.1+incompatible // indirect
-	github.com/docker/go-connections v0.4.0 // indirect
 	github.com/docker/go-units v0.4.0 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
 	github.com/golang/protobuf v1.5.2 // indirect
@@ -36,6 +39,5 @@ require (
 	google.golang.org/grpc v1.45.0 // indirect
 	google.golang.org/protobuf v1.27.1 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gotest.tools/v3 v3.1.0 // indirect
 )
@@ -0,0 +1,51 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CreateBootnodeKey generates the node key of a bootnode in the data dir, once,
+// so that its enode url stays the same between restarts
+func (c *Celo) CreateBootnodeKey() error {
+	datadir := c.config.DataDir
+	if _, err := os.Stat(filepath.Join(datadir, "nodekey")); err == nil {
+		return nil
+	}
+
+	if c.imageTools == "" {
+		return fmt.Errorf("network %q has no tools-image to run bootnode with", c.config.Network)
+	}
+
+	if err := os.MkdirAll(datadir, os.ModePerm); err != nil {
+		return err
+	}
+
+	log.Println("Generating bootnode key...")
+	_, err := c.runTools(datadir, "bootnode", "-genkey", "/root/.celo/nodekey")
+	return err
+}
+
+// BootnodeEnode returns the enode url other nodes use as `bootnodes`
+func (c *Celo) BootnodeEnode(config bootnodeConfig) (string, error) {
+	out, err := c.runTools(c.config.DataDir, "bootnode", "-nodekey", "/root/.celo/nodekey", "-writeaddress")
+	if err != nil {
+		return "", err
+	}
+
+	match := regexp.MustCompile(`([0-9a-fA-F]{128})`).FindStringSubmatch(out)
+	if match == nil {
+		return "", fmt.Errorf("no node id in output of `bootnode -writeaddress`: %s", out)
+	}
+
+	ip := config.ExternalIP
+	if ip == "" {
+		ip = "<host ip>"
+	}
+
+	return "enode://" + strings.ToLower(match[1]) + "@" + ip + ":" + config.Port, nil
+}
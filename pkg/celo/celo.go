@@ -10,7 +10,6 @@ import (
 	"regexp"
 	"strings"
 
-	"go.blockdaemon.com/bpm/celo/configs"
 	"go.blockdaemon.com/bpm/sdk/pkg/docker"
 	"go.blockdaemon.com/bpm/sdk/pkg/node"
 	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
@@ -20,665 +19,187 @@ import (
 type Celo struct {
 	image            string
 	imageAttestation string
+	imageTools       string
 	networkID        string
+	builtinNetwork   bool
+	genesisHash      string
 	cmdFile          string
 	n                node.Node
+	config           commonConfig // the parameters every subtype has, the others are in the config of kind
+	kind             Subtype
 	Subtype          string
 }
 
+// chainDir the directory celo keeps its chain data and node key in, inside the data dir
+const chainDir = "celo"
+
 // ICelo The Celo interface
 type ICelo interface {
-	GetParameters(string) []plugin.Parameter
-	SetImage(string) bool
+	GetParameters() []plugin.Parameter
+	GetContainers() []docker.Container
+	GetTemplates() map[string]string
+	GetNode() node.Node
+	Validate() error
+	PreConfigure() error
+	PreStart() error
+	PostStart() error
+	Tests() Tests
 }
 
+var _ ICelo = (*Celo)(nil)
+
 // New Returns a new Celo instance
-func New() *Celo {
+func New() (*Celo, error) {
 	var c Celo
 
 	n := buildNode()
+	network := n.StrParameters["network"]
+	c.Subtype = n.StrParameters["subtype"]
+	c.kind = lookupSubtype(c.Subtype)
+	c.n = n
 
-	// get the images & bootnodes
-	if n.StrParameters["network"] == "baklava" {
-		c.image = "us.gcr.io/celo-testnet/celo-node:baklava"
-		c.imageAttestation = "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4"
-		c.networkID = "62320"
-	} else if n.StrParameters["network"] == "mainnet" {
-		c.image = "us.gcr.io/celo-org/celo-node:mainnet"
-		c.imageAttestation = "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4"
-		c.networkID = "42220"
-	}
-
-	// get the default bootnodes
-	if n.StrParameters["network"] == "mainnet" && n.StrParameters["bootnodes"] == "" {
-		n.StrParameters["bootnodes"] = "enode://5c9a3afb564b48cc2fa2e06b76d0c5d8f6910e1930ea7d0930213a0cbc20450434cd442f6483688eff436ad14dc29cb90c9592cc5c1d27ca62f28d4d8475d932@34.82.79.155:30301,enode://2874c2abd970a043e9aae6ef1f07521f747776d38c8bd907b9e0c08d6b19c606e2f46c0539d829bc79e4053a2f53a0348b89ab35cb179748e157ef8c87acf120@34.75.29.120:30303"
-	} else if n.StrParameters["bootnodes"] == "" {
-		n.StrParameters["bootnodes"] = "enode://5aaf10664b12431c250597e980aacd7d5373cae00f128be5b00364344bb96bce7555b50973664bddebd1cb7a6d3fb927bec81527f80e22a26fa373c375fcdefc@35.247.103.141:30301,enode://5aaf10664b12431c250597e980aacd7d5373cae00f128be5b00364344bb96bce7555b50973664bddebd1cb7a6d3fb927bec81527f80e22a26fa373c375fcdefc@35.247.103.141:30301"
+	networks, err := LoadNetworks(n.StrParameters["network-file"])
+	if err != nil {
+		return nil, err
+	}
+
+	// get the images & network id
+	profile, ok := networks[network]
+	if !ok && !isMeta() { // meta is called without a node, so there is no network yet
+		return nil, fmt.Errorf("unknown network %q, must be one of: %s", network, strings.Join(networkNames(networks), ", "))
+	}
+	c.image = profile.Image
+	c.imageAttestation = profile.AttestationImage
+	c.imageTools = profile.ToolsImage
+	c.networkID = profile.NetworkID
+	c.builtinNetwork = profile.file == ""
+	c.genesisHash = profile.GenesisHash
+
+	// get the default bootnodes, a devnet only peers with itself
+	if ok && n.StrParameters["bootnodes"] == "" && c.hasParameter("bootnodes") {
+		if len(profile.Bootnodes) > 0 {
+			n.StrParameters["bootnodes"] = strings.Join(profile.Bootnodes, ",")
+		} else if isConfiguring() { // reading the image runs a container, only worth it when the config is written
+			bootnodes, err := imageBootnodes(c.image)
+			if err != nil {
+				return nil, fmt.Errorf("unable to get default %s bootnodes: %s", network, err)
+			}
+			n.StrParameters["bootnodes"] = bootnodes
+		}
 	}
 
 	c.cmdFile = "celo.dockercmd"
-	c.n = n
-	c.Subtype = n.StrParameters["subtype"]
+	if err := c.decodeConfig(); err != nil {
+		return nil, err
+	}
+	c.config = c.kind.Config().common()
 
-	return &c
+	return &c, nil
 }
 
-// GetParameters returns parameters for a subtype, defaults to all
+// GetParameters returns the parameters of the subtype, all of them for meta
 func (c *Celo) GetParameters() []plugin.Parameter {
-
-	var params []plugin.Parameter
-	subtype := c.Subtype
-
-	pSubtype := plugin.Parameter{
-		Name:        "subtype",
-		Type:        plugin.ParameterTypeString,
-		Description: "The type of node. Must be either `validator`, `proxy` or `fullnode`",
-		Mandatory:   false,
-		Default:     "fullnode",
-	}
-	pNetwork := plugin.Parameter{
-		Name:        "network",
-		Type:        plugin.ParameterTypeString,
-		Description: "Mainnet or baklava testnet",
-		Mandatory:   true,
-		Default:     "baklava",
-	}
-	pNetworkID := plugin.Parameter{
-		Name:        "networkid",
-		Type:        plugin.ParameterTypeString,
-		Description: "The current Celo network id",
-		Mandatory:   true,
-		Default:     c.networkID,
-	}
-	pSigner := plugin.Parameter{
-		Name:        "signer",
-		Type:        plugin.ParameterTypeString,
-		Description: "The signer address",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pNoUSB := plugin.Parameter{
-		Name:        "nousb",
-		Type:        plugin.ParameterTypeString,
-		Description: "Boolean. Wether to expect usb connections, eg ledger",
-		Mandatory:   false,
-		Default:     "true",
-	}
-	pValidator := plugin.Parameter{
-		Name:        "validator",
-		Type:        plugin.ParameterTypeString,
-		Description: "The validator address",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pBootnodes := plugin.Parameter{
-		Name:        "bootnodes",
-		Type:        plugin.ParameterTypeString,
-		Description: "List of bootnodes to connect to",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pKeystore := plugin.Parameter{
-		Name:        "keystore-file",
-		Type:        plugin.ParameterTypeString,
-		Description: "Location of the signer keystore json",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pKeypass := plugin.Parameter{
-		Name:        "keystore-pass",
-		Type:        plugin.ParameterTypeString,
-		Description: "The password for the keystore json",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pPort := plugin.Parameter{
-		Name:        "port",
-		Type:        plugin.ParameterTypeString,
-		Description: "Port to listen to",
-		Mandatory:   false,
-		Default:     "30303",
-	}
-	pProxyInternal := plugin.Parameter{
-		Name:        "proxy_internal",
-		Type:        plugin.ParameterTypeString,
-		Description: "The internal proxy ip, use external if none",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pProxyExternal := plugin.Parameter{
-		Name:        "proxy_external",
-		Type:        plugin.ParameterTypeString,
-		Description: "The external proxy ip",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pEnode := plugin.Parameter{
-		Name:        "enode",
-		Type:        plugin.ParameterTypeString,
-		Description: "The proxy enode id",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pRpcaddr := plugin.Parameter{
-		Name:        "rpcaddr",
-		Type:        plugin.ParameterTypeString,
-		Description: "The rpcaddr ip address",
-		Mandatory:   false,
-		Default:     "0.0.0.0",
-	}
-	pRPCPort := plugin.Parameter{
-		Name:        "rpcport",
-		Type:        plugin.ParameterTypeString,
-		Description: "The rpc port for the hostt",
-		Mandatory:   false,
-		Default:     "8545",
-	}
-	pLightServe := plugin.Parameter{
-		Name:        "light_serve",
-		Type:        plugin.ParameterTypeString,
-		Description: "light.serve",
-		Mandatory:   false,
-		Default:     "90",
-	}
-	pLightMaxpeers := plugin.Parameter{
-		Name:        "light_maxpeers",
-		Type:        plugin.ParameterTypeString,
-		Description: "The max peers `light.maxpeers`",
-		Mandatory:   false,
-		Default:     "1000",
-	}
-	pMaxpeers := plugin.Parameter{
-		Name:        "maxpeers",
-		Type:        plugin.ParameterTypeString,
-		Description: "The max peers `light.maxpeers`",
-		Mandatory:   false,
-		Default:     "1100",
-	}
-	pAccount := plugin.Parameter{
-		Name:        "account",
-		Type:        plugin.ParameterTypeString,
-		Description: "The account to send rewards to",
-		Mandatory:   false,
-		Default:     "",
-	}
-	// pCeloCommands := plugin.Parameter{
-	// 	Name:        "celo",
-	// 	Type:        plugin.ParameterTypeString,
-	// 	Description: "Extra commands for container. Example: `--celo=\"--rpcapi web,personal,debug --rpcport 1234 --rpchost 0.0.0.0\"",
-	// 	Mandatory:   false,
-	// 	Default:     "",
-	// }
-	pDBHost := plugin.Parameter{
-		Name:        "database",
-		Type:        plugin.ParameterTypeString,
-		Description: "Database URL for attestation service",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pDBPassword := plugin.Parameter{
-		Name:        "db_password",
-		Type:        plugin.ParameterTypeString,
-		Description: "Database password for attestation service postgres",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pDBUser := plugin.Parameter{
-		Name:        "db_user",
-		Type:        plugin.ParameterTypeString,
-		Description: "Database user for attestation service postgres",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pAttNode := plugin.Parameter{
-		Name:        "node_url",
-		Type:        plugin.ParameterTypeString,
-		Description: "Attestation node url, eg http://bpm-flower-pot-1234-attestattion-node:8545",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pTwilioServiceSID := plugin.Parameter{
-		Name:        "twilio_service_sid",
-		Type:        plugin.ParameterTypeString,
-		Description: "Twilio messaging service SID for attestation services",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pTwilioAccountSID := plugin.Parameter{
-		Name:        "twilio_account_sid",
-		Type:        plugin.ParameterTypeString,
-		Description: "Twilio account SID for attesation service",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pTwilioBlacklist := plugin.Parameter{
-		Name:        "twilio_blacklist",
-		Type:        plugin.ParameterTypeString,
-		Description: "Twilio blacklist for attesation service",
-		Mandatory:   false,
-		Default:     "",
-	}
-	pTwilioAuthToken := plugin.Parameter{
-		Name:        "twilio_auth_token",
-		Type:        plugin.ParameterTypeString,
-		Description: "Auth token for Twilio",
-		Mandatory:   false,
-		Default:     "",
-	}
-
-	switch subtype {
-
-	case "proxy":
-		pSigner.Mandatory = true
-		pBootnodes.Mandatory = true
-		params = []plugin.Parameter{
-			pNetwork,
-			pSubtype,
-			pNetworkID,
-			pRpcaddr,
-			pRPCPort,
-			pPort,
-			pSigner,
-			pBootnodes,
-			// pCeloCommands,
-		}
-	case "validator":
-		pSigner.Mandatory = true
-		pKeystore.Mandatory = true
-		pKeypass.Mandatory = true
-		pProxyInternal.Mandatory = true
-		pProxyExternal.Mandatory = true
-		pEnode.Mandatory = true
-		params = []plugin.Parameter{
-			pNetwork,
-			pSubtype,
-			pNetworkID,
-			pSigner,
-			pKeystore,
-			pKeypass,
-			pPort,
-			pProxyInternal,
-			pProxyExternal,
-			pEnode,
-			// pCeloCommands,
-		}
-	case "fullnode":
-		pBootnodes.Mandatory = true
-		pAccount.Mandatory = true
-		params = []plugin.Parameter{
-			pNetwork,
-			pSubtype,
-			pNetworkID,
-			pBootnodes,
-			pRpcaddr,
-			pRPCPort,
-			pLightServe,
-			pLightMaxpeers,
-			pMaxpeers,
-			pAccount,
-			pPort,
-			// pCeloCommands,
-			pNoUSB,
-		}
-	case "attestation-node":
-		pSigner.Mandatory = true
-		pKeystore.Mandatory = true
-		pKeypass.Mandatory = true
-		pBootnodes.Mandatory = true
-		params = []plugin.Parameter{
-			pNetwork,
-			pNetworkID,
-			pSigner,
-			pKeystore,
-			pKeypass,
-			pBootnodes,
-			pRpcaddr,
-			// pCeloCommands,
-		}
-	case "attestation-service":
-		pSigner.Mandatory = true
-		pValidator.Mandatory = true
-		pAttNode.Mandatory = true
-		pDBUser.Mandatory = true
-		pDBPassword.Mandatory = true
-		pTwilioServiceSID.Mandatory = true
-		pTwilioAccountSID.Mandatory = true
-		pTwilioAuthToken.Mandatory = true
-
-		// get default db url
-		if c.n.StrParameters["db_host"] == "" {
-			c.getDBUrl()
-		}
-
-		params = []plugin.Parameter{
-			pNetwork,
-			pSigner,
-			pValidator,
-			pDBHost,
-			pDBPassword,
-			pAttNode,
-			pTwilioServiceSID,
-			pTwilioAccountSID,
-			pTwilioAuthToken,
-			pPort,
-			pTwilioBlacklist,
-			// pCeloCommands,
-		}
-
-	default: // show all params so they appear in the bpm manifest
-		params = []plugin.Parameter{
-			pSubtype,
-			pNetwork,
-			pNetworkID,
-			pSigner,
-			pKeystore,
-			pKeypass,
-			pPort,
-			pProxyInternal,
-			pProxyExternal,
-			pEnode,
-			pBootnodes,
-			pRpcaddr,
-			pRPCPort,
-			pNoUSB,
-			pLightServe,
-			pLightMaxpeers,
-			pMaxpeers,
-			pAccount,
-			// pCeloCommands,
-			pValidator,
-			pDBHost,
-			pDBPassword,
-			pDBUser,
-			pAttNode,
-			pTwilioServiceSID,
-			pTwilioAccountSID,
-			pTwilioAuthToken,
-			pTwilioBlacklist,
-		}
-	}
-
-	return params
+	return c.kind.Parameters(c)
 }
 
-// GetContainers returns containers for a subtype, defaults to all
+// GetContainers returns the containers of the subtype
 func (c *Celo) GetContainers() []docker.Container {
+	return c.kind.Containers(c)
+}
 
-	collectorContainerName := "collector"
-	collectorImage := "docker.io/blockdaemon/celo-collector:0.0.5"
-	collectorEnvFile := "configs/collector.env"
-	postgresEnvFile := "configs/postgres.env"
-
-	var containers []docker.Container
-	subtype := c.Subtype
-	datadir := c.n.StrParameters["data-dir"]
-	n := c.n
-
-	cProxy := docker.Container{
-		Name:    "proxy",
-		Image:   c.image,
-		CmdFile: c.cmdFile,
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: datadir,
-				To:   "/root/.celo",
-			},
-		},
-		Ports: []docker.Port{
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "udp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      "30503",
-				ContainerPort: "30503",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        c.n.StrParameters["rpcaddr"],
-				HostPort:      c.n.StrParameters["rpcport"],
-				ContainerPort: "8545",
-			},
-		},
-		CollectLogs: true,
-	}
-	cValidator := docker.Container{
-		Name:    "validator",
-		Image:   c.image,
-		CmdFile: c.cmdFile,
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: datadir,
-				To:   "/root/.celo",
-			},
-			{
-				Type: "bind",
-				From: "./configs",
-				To:   "/root/.celo/configs",
-			},
-		},
-		Ports: []docker.Port{
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "udp",
-			},
-		},
-		CollectLogs: true,
-	}
-	cFullnode := docker.Container{
-		Name:    "fullnode",
-		Image:   c.image,
-		CmdFile: c.cmdFile,
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: datadir,
-				To:   "/root/.celo",
-			},
-		},
-		Ports: []docker.Port{
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "udp",
-			},
-			{
-				HostIP:        c.n.StrParameters["rpcaddr"],
-				HostPort:      c.n.StrParameters["rpcport"],
-				ContainerPort: "8545",
-			},
-		},
-		CollectLogs: true,
-	}
+// GetNode returns the current node
+func (c *Celo) GetNode() node.Node {
+	return c.n
+}
 
-	cAttestation := docker.Container{
-		Name:    "attestation-node",
-		Image:   c.image,
-		CmdFile: c.cmdFile,
-		Ports: []docker.Port{
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["rpcport"],
-				ContainerPort: "8545",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "tcp",
-			},
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: "30303",
-				Protocol:      "udp",
-			},
-		},
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: datadir,
-				To:   "/root/.celo",
-			},
-			{
-				Type: "bind",
-				From: "./configs",
-				To:   "/root/.celo/configs",
-			},
-		},
-		CollectLogs: true,
-	}
-	cAttestationService := docker.Container{
-		Name:    "attestation-service",
-		Image:   c.imageAttestation,
-		CmdFile: c.cmdFile,
-		Ports: []docker.Port{
-			{
-				HostIP:        "0.0.0.0",
-				HostPort:      n.StrParameters["port"],
-				ContainerPort: n.StrParameters["port"],
-				Protocol:      "tcp",
-			},
-		},
-		CollectLogs: false,
-		EnvFilename: "configs/attestation-service.env",
-	}
-	cNodestate := docker.Container{
-		Name:        collectorContainerName,
-		Image:       collectorImage,
-		EnvFilename: collectorEnvFile,
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: "logs",
-				To:   "/data/nodestate",
-			},
-		},
-		CollectLogs: true,
-	}
-	cPostgres := docker.Container{
-		Name:        "attestation-postgres",
-		Image:       "docker.io/library/postgres:13",
-		EnvFilename: postgresEnvFile,
-		CollectLogs: false,
+// GetTemplates Returns the templates for current node, already rendered by the plugin
+func (c *Celo) GetTemplates() map[string]string {
+	templates, err := c.kind.Templates(c)
+	if err != nil {
+		log.Fatalf("Unable to render templates: %s\n", err)
 	}
-
-	switch subtype {
-	case "proxy":
-		containers = []docker.Container{
-			cProxy,
-			cNodestate,
-		}
-	case "validator":
-		containers = []docker.Container{
-			cValidator,
-			cNodestate,
-		}
-	case "fullnode":
-		containers = []docker.Container{
-			cFullnode,
-			cNodestate,
-		}
-	case "attestation-node":
-		containers = []docker.Container{
-			cAttestation,
-		}
-	case "attestation-service":
-		containers = []docker.Container{
-			cPostgres,
-			cAttestationService,
-		}
-	default:
-		containers = []docker.Container{
-			cProxy,
-		}
+	for name, content := range templates {
+		templates[name] = escapeSDKTemplate(content)
 	}
+	return templates
+}
 
-	return containers
+// PreConfigure runs the hook of the subtype before create-configurations renders the templates
+func (c *Celo) PreConfigure() error {
+	return c.kind.PreConfigure(c)
 }
 
-// GetNode returns the current node
-func (c *Celo) GetNode() node.Node {
-	return c.n
+// PreStart runs the hook of the subtype before start runs the containers
+func (c *Celo) PreStart() error {
+	return c.kind.PreStart(c)
 }
 
-// GetTemplates Returns the templates for current node
-func (c *Celo) GetTemplates() map[string]string {
-	subtype := c.Subtype
-	dockerCmd := c.getDockerCmd()
+// PostStart runs the hook of the subtype once start runs the containers
+func (c *Celo) PostStart() error {
+	return c.kind.PostStart(c)
+}
 
-	templates := map[string]string{
-		"celo.dockercmd": dockerCmd,
-	}
+// Tests returns what the tester checks on the running node
+func (c *Celo) Tests() Tests {
+	return c.kind.Tests(c)
+}
 
-	if subtype != "attestation-service" {
-		templates["configs/collector.env"] = configs.CollectorEnvTpl
-	}
-	if subtype == "attestation-service" {
+// InitGenesis Call `geth init /celo/genesis.json` in mounted dir to provision a Celo node,
+// or with the `genesis-file` from the host if set.
+// Returns false without an error when the chain data was already initialized.
+func (c *Celo) InitGenesis() (bool, error) {
 
-		if c.n.StrParameters["db_host"] == "" {
-			c.getDBUrl()
+	// the expected hash only applies to the genesis in the image
+	if genesisFile, err := c.GenesisFile(); err != nil || genesisFile != "" {
+		if err == nil {
+			err = c.ValidateGenesisFile()
 		}
-		dbURL := c.n.StrParameters["db_host"]
-
-		templates["configs/attestation-service.env"] = strings.Replace(configs.AttesetationServiceEnvs, "{{ .Node.StrParameters.db_host }}", dbURL, -1)
-		templates["configs/postgres.env"] = configs.PostgresEnvs
-	}
-	if subtype == "validator" || subtype == "attestation-node" {
-		ks := c.getKeystore()
-		templates["configs/keystore/"+ks.filename] = ks.json // string
-		templates["configs/.password.secret"] = ks.pass
+		if err != nil {
+			return false, err
+		}
+		return c.initGenesis(c.config.DataDir, genesisFile, "")
 	}
 
-	return templates
+	return c.initGenesis(c.config.DataDir, "", c.genesisHash)
 }
 
-// InitGenesis Call `geth init /celo/genesis.json` in mounted dir to provision a Celo node.
-func (c *Celo) InitGenesis() (bool, error) {
+// initGenesis runs `geth init` in datadir, with the genesis of the image or genesisFile from the host,
+// and checks the written genesis against expectedHash if set
+func (c *Celo) initGenesis(datadir string, genesisFile string, expectedHash string) (bool, error) {
+
+	chaindata := filepath.Join(datadir, chainDir, "chaindata")
+	if _, err := os.Stat(chaindata); err == nil {
+		log.Printf("Genesis already initialized in %s, skipping\n", chaindata)
+		return false, nil
+	}
 
 	bm, err := docker.NewBasicManager(c.n)
 	if err != nil {
 		return false, err
 	}
 
-	container := docker.Container{
-		Name:  "celoinit",
-		Image: c.image,
-		Cmd:   []string{"--nousb", "init", "/celo/genesis.json"},
-		Mounts: []docker.Mount{
-			{
-				Type: "bind",
-				From: c.n.StrParameters["data-dir"],
-				To:   "/root/.celo",
-			},
+	genesis := "/celo/genesis.json"
+	mounts := []docker.Mount{
+		{
+			Type: "bind",
+			From: datadir,
+			To:   "/root/.celo",
 		},
+	}
+	if genesisFile != "" {
+		genesis = "/root/genesis.json"
+		mounts = append(mounts, docker.Mount{
+			Type: "bind",
+			From: genesisFile,
+			To:   genesis,
+		})
+	}
+
+	container := docker.Container{
+		Name:        "celoinit",
+		Image:       c.image,
+		Cmd:         []string{"--nousb", "init", genesis},
+		Mounts:      mounts,
 		CollectLogs: false,
 	}
 
@@ -688,35 +209,64 @@ func (c *Celo) InitGenesis() (bool, error) {
 		return false, err
 	}
 
-	reg := regexp.MustCompile(`(Successfully\swrote\sgenesis\sstate)`)
-	status := reg.FindSubmatch([]byte(stdOut))
-	if len(status[1]) <= 0 {
-		return false, fmt.Errorf("Unkown Error")
+	// geth logs the hash abbreviated on a terminal, eg `hash=19ea33…c7eb`
+	reg := regexp.MustCompile(`Successfully\swrote\sgenesis\sstate.*hash=(?:0x)?([0-9a-fA-F]+)(?:…|\.\.\.)?([0-9a-fA-F]*)`)
+	status := reg.FindStringSubmatch(stdOut)
+	if status == nil {
+		return false, fmt.Errorf("geth init did not write the genesis state: %s", strings.TrimSpace(stdOut))
+	}
+
+	if expectedHash != "" && !genesisHashMatches(expectedHash, status[1], status[2]) {
+		return false, fmt.Errorf("genesis hash %s…%s does not match %s expected for network %s", status[1], status[2], expectedHash, c.config.Network)
 	}
 
 	return true, nil
 }
 
-func (c *Celo) getDockerCmd() string {
-	subtype := c.Subtype
-
-	dockerCmd := ""
-	switch subtype {
-	case "proxy":
-		dockerCmd = configs.ProxyCmdTpl
-	case "validator":
-		dockerCmd = configs.ValidatorCmdTpl
-	case "fullnode":
-		dockerCmd = configs.FullnodeCmdTpl
-	case "attestation-node":
-		dockerCmd = configs.AttestationCmdTpl
-	case "attestation-service":
-		dockerCmd = configs.AttestationServiceCmdTpl
-	default:
-		dockerCmd = "--help" // docker command required by sdk?
+// genesisHashMatches compares a full hash against a hash that might be abbreviated to prefix…suffix
+func genesisHashMatches(expected string, prefix string, suffix string) bool {
+	expected = strings.ToLower(strings.TrimPrefix(expected, "0x"))
+	prefix = strings.ToLower(prefix)
+	suffix = strings.ToLower(suffix)
+
+	if suffix == "" {
+		return expected == prefix
 	}
 
-	return strings.Replace(dockerCmd, "{{ .Node.StrParameters.networkid }}", c.networkID, -1)
+	return strings.HasPrefix(expected, prefix) && strings.HasSuffix(expected, suffix)
+}
+
+// getDockerCmd renders the docker command of the subtype with the `celo` flags merged in,
+// it fails if a flag ends up set twice, in the command or in both the command and `config.toml`
+func (c *Celo) getDockerCmd() (string, error) {
+	dockerCmd, err := renderTemplate(c.cmdFile, c.kind.DockerCmdTpl(), c.templateData())
+	if err != nil {
+		return "", err
+	}
+
+	configFlags := c.gethConfigFlags()
+	if err := checkDuplicateFlags(dockerCmd, configFlags); err != nil {
+		return "", err
+	}
+
+	if chain := c.chain(); chain != nil {
+		flags, err := parseFlags(chain.Celo)
+		if err != nil {
+			return "", fmt.Errorf("celo: %s", err)
+		}
+		if len(flags) > 0 {
+			if dockerCmd, err = mergeFlags(dockerCmd, flags, configFlags, chain.CeloOverride); err != nil {
+				return "", fmt.Errorf("celo: %s", err)
+			}
+		}
+	}
+
+	// `celo` flags that override config.toml settings are meant to be set twice
+	if err := checkDuplicateFlags(dockerCmd, nil); err != nil {
+		return "", err
+	}
+
+	return dockerCmd, nil
 }
 
 type keystore struct {
@@ -725,11 +275,10 @@ type keystore struct {
 	json     string
 }
 
-func (c *Celo) getKeystore() keystore {
+func (c *Celo) getKeystore(config keystoreConfig) keystore {
 
-	n := c.n
-	file := n.StrParameters["keystore-file"]
-	pass := n.StrParameters["keystore-pass"]
+	file := config.KeystoreFile
+	pass := config.KeystorePass
 
 	content, err := ioutil.ReadFile(file)
 	if err != nil {
@@ -746,7 +295,7 @@ func (c *Celo) getKeystore() keystore {
 		json:     string(content),
 	}
 
-	nodeDir := n.NodeDirectory()
+	nodeDir := c.n.NodeDirectory()
 	targetDir := nodeDir + "/configs/keystore"
 	if err := os.MkdirAll(targetDir, os.ModePerm); err != nil {
 		fmt.Printf("Error creating keystore directory: %s\n", err)
@@ -755,9 +304,22 @@ func (c *Celo) getKeystore() keystore {
 	return ks
 }
 
-func (c *Celo) getDBUrl() {
-	postgres := "bpm-" + c.n.ID + "-attestation-postgres" + ":5432"
-	c.n.StrParameters["db_host"] = "postgres://" + c.n.StrParameters["db_user"] + ":" + c.n.StrParameters["db_password"] + "@" + postgres
+// chain returns the geth parameters of a subtype running geth on the chain of the network, nil for the others
+func (c *Celo) chain() *chainConfig {
+	if config, ok := c.kind.Config().(interface{ chain() *chainConfig }); ok {
+		return config.chain()
+	}
+	return nil
+}
+
+// hasParameter checks if a parameter belongs to the current subtype
+func (c *Celo) hasParameter(name string) bool {
+	for _, p := range c.GetParameters() {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
 }
 
 func buildNode() node.Node {
@@ -771,7 +333,7 @@ func buildNode() node.Node {
 			jsonfile = arg
 		}
 	}
-	if os.Args[1] != "meta" {
+	if !isMeta() {
 		n, err = node.Load(jsonfile)
 		if err != nil {
 			log.Fatalf("Unable to load node json: %s\n", err)
@@ -782,3 +344,12 @@ func buildNode() node.Node {
 
 	return n
 }
+
+// isConfiguring returns true for the commands that render and use the configuration of the node
+func isConfiguring() bool {
+	return len(os.Args) > 1 && (os.Args[1] == "create-configurations" || os.Args[1] == "start")
+}
+
+func isMeta() bool {
+	return len(os.Args) > 1 && os.Args[1] == "meta"
+}
@@ -0,0 +1,33 @@
+package celo
+
+import "testing"
+
+func TestGenesisHashMatches(t *testing.T) {
+	const hash = "0x19ea3339d3c8cda97235bc8293240d5b9dadcdfbb5d4b0b90ee731cac1bd11c3"
+
+	tests := []struct {
+		name     string
+		expected string
+		prefix   string
+		suffix   string
+		want     bool
+	}{
+		{"full hash", hash, "19ea3339d3c8cda97235bc8293240d5b9dadcdfbb5d4b0b90ee731cac1bd11c3", "", true},
+		{"full hash, expected without 0x", hash[2:], "19ea3339d3c8cda97235bc8293240d5b9dadcdfbb5d4b0b90ee731cac1bd11c3", "", true},
+		{"full hash, upper case", hash, "19EA3339D3C8CDA97235BC8293240D5B9DADCDFBB5D4B0B90EE731CAC1BD11C3", "", true},
+		{"full hash differs", hash, "29ea3339d3c8cda97235bc8293240d5b9dadcdfbb5d4b0b90ee731cac1bd11c3", "", false},
+		{"prefix without suffix", hash, "19ea33", "", false},
+		{"abbreviated", hash, "19ea33", "bd11c3", true},
+		{"abbreviated, upper case", hash, "19EA33", "BD11C3", true},
+		{"abbreviated, prefix differs", hash, "29ea33", "bd11c3", false},
+		{"abbreviated, suffix differs", hash, "19ea33", "bd11c4", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := genesisHashMatches(tt.expected, tt.prefix, tt.suffix); got != tt.want {
+				t.Errorf("genesisHashMatches(%s, %s, %s) = %v, want %v", tt.expected, tt.prefix, tt.suffix, got, tt.want)
+			}
+		})
+	}
+}
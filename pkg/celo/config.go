@@ -0,0 +1,135 @@
+package celo
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// GethConfig the settings of `config.toml` and the geth flags they replace, flags in `celo` that
+// set the same settings conflict with the config unless `celo_override` is set
+type GethConfig struct {
+	values map[string]interface{}
+	flags  map[string]bool
+}
+
+// newGethConfig returns the settings every subtype running geth with `config.toml` shares
+func newGethConfig(c *Celo, config chainConfig) *GethConfig {
+	g := &GethConfig{values: map[string]interface{}{}, flags: map[string]bool{}}
+
+	networkID, _ := strconv.ParseUint(config.NetworkID, 10, 64)
+	g.set("Eth", "NetworkId", networkID, "networkid")
+	g.set("Eth", "SyncMode", "full", "syncmode")
+	g.set("Node.P2P", "ListenAddr", ":"+config.Port, "port")
+	g.set("Node", "HTTPVirtualHosts", []string{"bpm-" + c.n.ID + "-" + c.Subtype}, "rpcvhosts")
+
+	return g
+}
+
+// set sets a setting in a section like `Node.P2P`, flags are the geth flags of the same setting
+func (g *GethConfig) set(section string, key string, value interface{}, flags ...string) {
+	table := g.values
+	for _, name := range strings.Split(section, ".") {
+		sub, ok := table[name].(map[string]interface{})
+		if !ok {
+			sub = map[string]interface{}{}
+			table[name] = sub
+		}
+		table = sub
+	}
+	table[key] = value
+
+	for _, f := range flags {
+		g.flags[f] = true
+	}
+}
+
+// rpc serves the json rpc and, if enabled, the websocket endpoint. The container always listens on all
+// interfaces, for other containers on the docker network, `rpcaddr` and `wsaddr` are where docker publishes the ports.
+func (g *GethConfig) rpc(config rpcConfig) {
+	g.set("Node", "HTTPHost", "0.0.0.0", "rpc", "rpcaddr")
+	g.set("Node", "HTTPModules", config.RPCAPI, "rpcapi")
+	if len(config.RPCCorsDomain) > 0 {
+		g.set("Node", "HTTPCors", config.RPCCorsDomain, "rpccorsdomain")
+	}
+	if config.WS {
+		g.set("Node", "WSHost", "0.0.0.0", "ws", "wsaddr")
+		g.set("Node", "WSPort", 8546, "wsport")
+		g.set("Node", "WSModules", config.WSAPI, "wsapi")
+	}
+}
+
+func (g *GethConfig) bootnodes(bootnodes []string) {
+	g.set("Node.P2P", "BootstrapNodes", bootnodes, "bootnodes")
+}
+
+func (g *GethConfig) peers(maxPeers int) {
+	g.set("Node.P2P", "MaxPeers", maxPeers, "maxpeers")
+}
+
+func (g *GethConfig) nousb(noUSB bool) {
+	g.set("Node", "NoUSB", noUSB, "nousb")
+}
+
+func (g *GethConfig) keystore() {
+	g.set("Node", "KeyStoreDir", "/root/.celo/configs/keystore", "keystore")
+}
+
+// gethConfigFlags returns the flags of the settings in `config.toml`, none if the subtype has no config
+func (c *Celo) gethConfigFlags() map[string]bool {
+	if g := c.kind.GethConfig(c); g != nil {
+		return g.flags
+	}
+	return map[string]bool{}
+}
+
+// getGethConfigToml renders `config.toml`, with the partial toml of `config_toml` merged over the generated settings
+func (c *Celo) getGethConfigToml() (string, error) {
+	g := c.kind.GethConfig(c)
+	if g == nil {
+		return "", fmt.Errorf("subtype %s does not run geth with config.toml", c.Subtype)
+	}
+	values := g.values
+
+	if file := c.chain().ConfigToml; file != "" {
+		var user map[string]interface{}
+		if _, err := toml.DecodeFile(file, &user); err != nil {
+			return "", fmt.Errorf("unable to parse %s: %s", file, err)
+		}
+		mergeTables(values, user)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(values); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// mergeTables merges src into dst, tables are merged key by key and any other value replaces the one in dst
+func mergeTables(dst map[string]interface{}, src map[string]interface{}) {
+	for key, value := range src {
+		srcTable, srcOK := value.(map[string]interface{})
+		dstTable, dstOK := dst[key].(map[string]interface{})
+		if srcOK && dstOK {
+			mergeTables(dstTable, srcTable)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// splitList splits a comma separated parameter, an empty parameter is an empty list
+func splitList(value string) []string {
+	list := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
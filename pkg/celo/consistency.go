@@ -0,0 +1,115 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+)
+
+// checkHost compares the node against the other bpm nodes on this host. Ports that are
+// already bound are returned as problems, a validator signer that differs from its
+// proxy is only a warning as the proxy might be reconfigured next.
+func (c *Celo) checkHost() []string {
+	var problems []string
+
+	others, err := bpmContainers()
+	if err != nil {
+		log.Printf("WARNING: unable to check other bpm nodes on this host: %s\n", err)
+		return problems
+	}
+
+	own := map[string]bool{}
+	for _, container := range c.GetContainers() {
+		own["/bpm-"+c.n.ID+"-"+container.Name] = true
+	}
+
+	for _, container := range c.GetContainers() {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = "tcp"
+			}
+			for _, other := range others {
+				if own[other.Name] || !bindsPort(other, port.HostIP, port.HostPort, protocol) {
+					continue
+				}
+				problems = append(problems, fmt.Sprintf("%s/%s: already bound by %s on this host", port.HostPort, protocol, strings.TrimPrefix(other.Name, "/")))
+			}
+		}
+	}
+
+	if config, ok := c.kind.Config().(*validatorConfig); ok {
+		checkProxySigner(*config, others)
+	}
+
+	return problems
+}
+
+// checkProxySigner warns when a proxy of the validator proxies another validator than `signer`
+func checkProxySigner(config validatorConfig, others []types.ContainerJSON) {
+	signer := config.Signer
+	proxies, _ := config.validatorProxies()
+	proxyIPs := map[string]bool{}
+	for _, p := range proxies {
+		proxyIPs[p.internalIP] = true
+	}
+
+	for _, other := range others {
+		if other.Config == nil || other.NetworkSettings == nil || !hasArg(other.Args, "--proxy.proxy") {
+			continue
+		}
+
+		paired := false
+		for _, network := range other.NetworkSettings.Networks {
+			paired = paired || proxyIPs[network.IPAddress]
+		}
+		if !paired {
+			continue
+		}
+
+		for _, arg := range other.Args {
+			proxied := strings.TrimPrefix(arg, "--proxy.proxiedvalidatoraddress=")
+			if proxied != arg && !strings.EqualFold(proxied, signer) {
+				log.Printf("WARNING: validator signer %s differs from %s proxied by %s\n", signer, proxied, strings.TrimPrefix(other.Name, "/"))
+			}
+		}
+	}
+}
+
+// bindsPort checks if a container publishes hostPort/protocol on an overlapping host ip
+func bindsPort(container types.ContainerJSON, hostIP string, hostPort string, protocol string) bool {
+	if container.HostConfig == nil {
+		return false
+	}
+
+	for port, bindings := range container.HostConfig.PortBindings {
+		if port.Proto() != protocol {
+			continue
+		}
+		for _, binding := range bindings {
+			if binding.HostPort == hostPort && ipsOverlap(binding.HostIP, hostIP) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func ipsOverlap(a string, b string) bool {
+	wildcard := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+	return wildcard(a) || wildcard(b) || a == b
+}
+
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,371 @@
+package celo
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+)
+
+// devnet the generated validator set of a private chain, persisted as `devnet.json` in the data dir
+type devnet struct {
+	NetworkID  string            `json:"networkid"`
+	Validators []devnetValidator `json:"validators"`
+}
+
+type devnetValidator struct {
+	Address      string `json:"address"`
+	BLSPublicKey string `json:"bls_public_key"`
+	NodeID       string `json:"node_id"`
+}
+
+// CreateDevnet generates the validator keys and the genesis of a private chain.
+// An existing devnet in the data dir is reused so that the chain survives reconfiguration.
+func (c *Celo) CreateDevnet(config devnetConfig) error {
+
+	count := config.Validators
+	if count < 1 {
+		return fmt.Errorf("validators must be a number greater than 0, got %d", count)
+	}
+
+	if dn, err := c.loadDevnet(); err == nil {
+		if len(dn.Validators) != count || dn.NetworkID != config.NetworkID {
+			return fmt.Errorf("devnet in %s was created with %d validators on network id %s, remove it to recreate", c.config.DataDir, len(dn.Validators), dn.NetworkID)
+		}
+		log.Println("Using existing devnet...")
+		return nil
+	}
+
+	if c.imageTools == "" {
+		return fmt.Errorf("network %q has no tools-image to generate devnet keys with", c.config.Network)
+	}
+
+	dn := devnet{NetworkID: config.NetworkID}
+	for i := 0; i < count; i++ {
+		log.Printf("Generating keys for devnet validator %d...\n", i)
+		v, err := c.createDevnetValidator(i)
+		if err != nil {
+			return fmt.Errorf("unable to create devnet validator %d: %s", i, err)
+		}
+		dn.Validators = append(dn.Validators, v)
+	}
+
+	genesis, err := devnetGenesis(dn)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(c.devnetGenesisFile(), genesis, 0644); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(dn, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.devnetFile(), content, 0644)
+}
+
+// ConnectDevnet adds every validator as static peer of the others. Container ips are
+// only known once started, they are also written to `static-nodes.json` for restarts.
+func (c *Celo) ConnectDevnet() error {
+
+	dn, err := c.loadDevnet()
+	if err != nil {
+		return err
+	}
+
+	var enodes []string
+	for i, v := range dn.Validators {
+		ip, err := containerIP(c.devnetContainerName(i))
+		if err != nil {
+			return err
+		}
+		enodes = append(enodes, "enode://"+v.NodeID+"@"+ip+":30303")
+	}
+
+	for i := range dn.Validators {
+		var peers []string
+		for j, enode := range enodes {
+			if i != j {
+				peers = append(peers, enode)
+			}
+		}
+
+		staticNodesFile := filepath.Join(c.devnetValidatorDir(i), chainDir, "static-nodes.json")
+		if err := ioutil.WriteFile(staticNodesFile, []byte(staticNodes(peers)), 0644); err != nil {
+			return err
+		}
+
+		for _, peer := range peers {
+			if err := addPeer(c.devnetContainerName(i), peer); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// InitDevnetGenesis initializes every validator with the generated genesis.
+// Returns false without an error when all validators were already initialized.
+func (c *Celo) InitDevnetGenesis() (bool, error) {
+	dn, err := c.loadDevnet()
+	if err != nil {
+		return false, fmt.Errorf("unable to load devnet, run create-configurations first: %s", err)
+	}
+
+	initialized := false
+	for i := range dn.Validators {
+		ok, err := c.initGenesis(c.devnetValidatorDir(i), c.devnetGenesisFile(), "")
+		if err != nil {
+			return false, err
+		}
+		initialized = initialized || ok
+	}
+
+	return initialized, nil
+}
+
+func (c *Celo) createDevnetValidator(i int) (devnetValidator, error) {
+	var v devnetValidator
+
+	dir := c.devnetValidatorDir(i)
+	if err := os.MkdirAll(filepath.Join(dir, chainDir), os.ModePerm); err != nil {
+		return v, err
+	}
+
+	password := make([]byte, 16)
+	if _, err := rand.Read(password); err != nil {
+		return v, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, ".password.secret"), []byte(hex.EncodeToString(password)), 0600); err != nil {
+		return v, err
+	}
+
+	out, err := c.runTools(dir, "geth", "--nousb", "account", "new", "--datadir", "/root/.celo", "--password", "/root/.celo/.password.secret")
+	if err != nil {
+		return v, err
+	}
+	match := regexp.MustCompile(`(?:Public address of the key:\s*|Address: \{)(?:0x)?([0-9a-fA-F]{40})`).FindStringSubmatch(out)
+	if match == nil {
+		return v, fmt.Errorf("no address in output of `geth account new`: %s", out)
+	}
+	v.Address = "0x" + strings.ToLower(match[1])
+
+	out, err = c.runTools(dir, "geth", "--nousb", "account", "proof-of-possession", v.Address, v.Address, "--bls", "--datadir", "/root/.celo", "--password", "/root/.celo/.password.secret")
+	if err != nil {
+		return v, err
+	}
+	match = regexp.MustCompile(`Public Key:\s*(?:0x)?([0-9a-fA-F]{192})`).FindStringSubmatch(out)
+	if match == nil {
+		return v, fmt.Errorf("no bls public key in output of `geth account proof-of-possession`: %s", out)
+	}
+	v.BLSPublicKey = strings.ToLower(match[1])
+
+	nodekey := "/root/.celo/" + chainDir + "/nodekey"
+	if _, err := c.runTools(dir, "bootnode", "-genkey", nodekey); err != nil {
+		return v, err
+	}
+	out, err = c.runTools(dir, "bootnode", "-nodekey", nodekey, "-writeaddress")
+	if err != nil {
+		return v, err
+	}
+	match = regexp.MustCompile(`([0-9a-fA-F]{128})`).FindStringSubmatch(out)
+	if match == nil {
+		return v, fmt.Errorf("no node id in output of `bootnode -writeaddress`: %s", out)
+	}
+	v.NodeID = strings.ToLower(match[1])
+
+	return v, nil
+}
+
+// addPeer calls `admin.addPeer` in a running container, retrying until geth accepts ipc connections
+func addPeer(containerName string, enode string) error {
+	var err error
+	for retries := 0; retries < 30; retries++ {
+		var out string
+		out, err = gethExec(containerName, "admin.addPeer(\""+enode+"\")")
+		if err == nil && strings.TrimSpace(out) == "true" {
+			return nil
+		}
+		if err == nil {
+			err = fmt.Errorf("unable to add peer to %s: %s", containerName, out)
+		}
+		time.Sleep(2 * time.Second)
+	}
+
+	return err
+}
+
+// devnetGenesis renders a genesis with all validators in the initial istanbul validator set
+func devnetGenesis(dn devnet) ([]byte, error) {
+
+	chainID, err := strconv.ParseUint(dn.NetworkID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid devnet network id %q: %s", dn.NetworkID, err)
+	}
+
+	var addresses, blsKeys [][]byte
+	alloc := map[string]map[string]string{}
+	for _, v := range dn.Validators {
+		address, err := hex.DecodeString(strings.TrimPrefix(v.Address, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		blsKey, err := hex.DecodeString(v.BLSPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, rlpBytes(address))
+		blsKeys = append(blsKeys, rlpBytes(blsKey))
+
+		// 1,000,000 CELO for each validator
+		balance := new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil)
+		alloc[strings.TrimPrefix(v.Address, "0x")] = map[string]string{"balance": balance.String()}
+	}
+
+	// IstanbulExtra: validators, bls keys, removed validators, seal, aggregated seal, parent aggregated seal
+	emptySeal := rlpList(rlpBytes(nil), rlpBytes(nil), rlpBytes(nil))
+	extra := rlpList(rlpList(addresses...), rlpList(blsKeys...), rlpBytes(nil), rlpBytes(nil), emptySeal, emptySeal)
+	vanity := make([]byte, 32)
+
+	genesis := map[string]interface{}{
+		"config": map[string]interface{}{
+			"homesteadBlock":      0,
+			"eip150Block":         0,
+			"eip155Block":         0,
+			"eip158Block":         0,
+			"byzantiumBlock":      0,
+			"constantinopleBlock": 0,
+			"petersburgBlock":     0,
+			"istanbulBlock":       0,
+			"chainId":             chainID,
+			"istanbul": map[string]interface{}{
+				"epoch":          17280,
+				"policy":         2,
+				"blockperiod":    5,
+				"requesttimeout": 3000,
+				"lookbackwindow": 12,
+			},
+		},
+		"nonce":      "0x0",
+		"timestamp":  "0x0",
+		"gasLimit":   "0x1312d00",
+		"difficulty": "0x1",
+		"coinbase":   "0x0000000000000000000000000000000000000000",
+		"extraData":  "0x" + hex.EncodeToString(append(vanity, extra...)),
+		"alloc":      alloc,
+	}
+
+	return json.MarshalIndent(genesis, "", "  ")
+}
+
+func rlpBytes(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return b
+	}
+	return append(rlpHeader(0x80, len(b)), b...)
+}
+
+func rlpList(items ...[]byte) []byte {
+	var payload []byte
+	for _, item := range items {
+		payload = append(payload, item...)
+	}
+	return append(rlpHeader(0xc0, len(payload)), payload...)
+}
+
+func rlpHeader(offset byte, size int) []byte {
+	if size < 56 {
+		return []byte{offset + byte(size)}
+	}
+
+	var sizeBytes []byte
+	for s := size; s > 0; s >>= 8 {
+		sizeBytes = append([]byte{byte(s)}, sizeBytes...)
+	}
+	return append([]byte{offset + 55 + byte(len(sizeBytes))}, sizeBytes...)
+}
+
+// getDevnetContainers returns one container per validator, the first one serves rpc
+func (c *Celo) getDevnetContainers(config devnetConfig) []docker.Container {
+	var containers []docker.Container
+
+	for i := 0; i < config.Validators; i++ {
+		container := docker.Container{
+			Name:    fmt.Sprintf("devnet-validator-%d", i),
+			Image:   c.image,
+			CmdFile: fmt.Sprintf("devnet-validator-%d.dockercmd", i),
+			Mounts: []docker.Mount{
+				{
+					Type: "bind",
+					From: c.devnetValidatorDir(i),
+					To:   "/root/.celo",
+				},
+			},
+			CollectLogs: true,
+		}
+		if i == 0 {
+			container.Ports = []docker.Port{
+				{
+					HostIP:        config.RPCAddr,
+					HostPort:      config.RPCPort,
+					ContainerPort: "8545",
+				},
+			}
+		}
+		containers = append(containers, container)
+	}
+
+	return containers
+}
+
+func (c *Celo) loadDevnet() (devnet, error) {
+	var dn devnet
+
+	content, err := ioutil.ReadFile(c.devnetFile())
+	if err != nil {
+		return dn, err
+	}
+
+	err = json.Unmarshal(content, &dn)
+	return dn, err
+}
+
+// getDevnetCmd renders the docker command of a single devnet validator
+func (c *Celo) getDevnetCmd(i int, v devnetValidator) (string, error) {
+	data := c.templateData()
+	data.Validator = v
+	data.ValidatorIndex = i
+
+	return renderTemplate(fmt.Sprintf("devnet-validator-%d.dockercmd", i), configs.DevnetValidatorCmdTpl, data)
+}
+
+func (c *Celo) devnetFile() string {
+	return filepath.Join(c.config.DataDir, "devnet.json")
+}
+
+func (c *Celo) devnetGenesisFile() string {
+	return filepath.Join(c.config.DataDir, "genesis.json")
+}
+
+func (c *Celo) devnetValidatorDir(i int) string {
+	return filepath.Join(c.config.DataDir, fmt.Sprintf("validator-%d", i))
+}
+
+func (c *Celo) devnetContainerName(i int) string {
+	return "bpm-" + c.n.ID + "-" + fmt.Sprintf("devnet-validator-%d", i)
+}
@@ -0,0 +1,113 @@
+package celo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRLPBytes(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"empty", nil, "80"},
+		{"single byte below 0x80", []byte{0x0f}, "0f"},
+		{"single byte 0x80", []byte{0x80}, "8180"},
+		{"short string", []byte("dog"), "83646f67"},
+		{"55 bytes", bytes.Repeat([]byte{0xaa}, 55), "b7" + strings.Repeat("aa", 55)},
+		{"56 bytes", bytes.Repeat([]byte{0xaa}, 56), "b838" + strings.Repeat("aa", 56)},
+		{"1024 bytes", bytes.Repeat([]byte{0xaa}, 1024), "b90400" + strings.Repeat("aa", 1024)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(rlpBytes(tt.in)); got != tt.want {
+				t.Errorf("rlpBytes() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRLPList(t *testing.T) {
+	tests := []struct {
+		name  string
+		items [][]byte
+		want  string
+	}{
+		{"empty", nil, "c0"},
+		{"two strings", [][]byte{rlpBytes([]byte("cat")), rlpBytes([]byte("dog"))}, "c88363617483646f67"},
+		{"nested empty lists", [][]byte{rlpList(), rlpList(rlpList())}, "c3c0c1c0"},
+		{"55 bytes payload", [][]byte{rlpBytes(bytes.Repeat([]byte{0xaa}, 54))}, "f7b6" + strings.Repeat("aa", 54)},
+		{"56 bytes payload", [][]byte{rlpBytes(bytes.Repeat([]byte{0xaa}, 55))}, "f838b7" + strings.Repeat("aa", 55)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(rlpList(tt.items...)); got != tt.want {
+				t.Errorf("rlpList() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevnetGenesis(t *testing.T) {
+	validator := devnetValidator{
+		Address:      "0x" + strings.Repeat("11", 20),
+		BLSPublicKey: strings.Repeat("22", 96),
+	}
+
+	// vanity, then the istanbul extra: one validator, its bls key, no removed validators, empty seals
+	emptySeal := "c3808080"
+	extra := strings.Repeat("00", 32) + "f884" +
+		"d594" + strings.Repeat("11", 20) +
+		"f862b860" + strings.Repeat("22", 96) +
+		"8080" + emptySeal + emptySeal
+
+	tests := []struct {
+		name    string
+		dn      devnet
+		wantErr bool
+	}{
+		{"one validator", devnet{NetworkID: "1101", Validators: []devnetValidator{validator}}, false},
+		{"network id not a number", devnet{NetworkID: "devnet", Validators: []devnetValidator{validator}}, true},
+		{"address not hex", devnet{NetworkID: "1101", Validators: []devnetValidator{{Address: "0xzz", BLSPublicKey: validator.BLSPublicKey}}}, true},
+		{"bls key not hex", devnet{NetworkID: "1101", Validators: []devnetValidator{{Address: validator.Address, BLSPublicKey: "zz"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := devnetGenesis(tt.dn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("devnetGenesis() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var genesis struct {
+				Config struct {
+					ChainID uint64 `json:"chainId"`
+				} `json:"config"`
+				ExtraData string                       `json:"extraData"`
+				Alloc     map[string]map[string]string `json:"alloc"`
+			}
+			if err := json.Unmarshal(content, &genesis); err != nil {
+				t.Fatalf("devnetGenesis() is not json: %s", err)
+			}
+
+			if genesis.Config.ChainID != 1101 {
+				t.Errorf("chainId = %d, want 1101", genesis.Config.ChainID)
+			}
+			if genesis.ExtraData != "0x"+extra {
+				t.Errorf("extraData = %s, want 0x%s", genesis.ExtraData, extra)
+			}
+			if balance := genesis.Alloc[strings.Repeat("11", 20)]["balance"]; balance != "1"+strings.Repeat("0", 24) {
+				t.Errorf("validator balance = %q, want 10^24", balance)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package celo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+)
+
+// imageBootnodes reads the comma separated bootnodes shipped in `/celo/bootnodes` of a celo-node image.
+func imageBootnodes(image string) (string, error) {
+	out, err := readImageFile(image, "/celo/bootnodes")
+	if err != nil {
+		return "", err
+	}
+
+	bootnodes := strings.Join(strings.Fields(strings.Replace(out, ",", " ", -1)), ",")
+	if bootnodes == "" {
+		return "", fmt.Errorf("no bootnodes found in %s", image)
+	}
+
+	return bootnodes, nil
+}
+
+// readImageFile returns the content of a file inside an image, the same as
+// `docker run --rm --entrypoint cat $image $file`.
+func readImageFile(image string, file string) (string, error) {
+	ctx := context.Background()
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	if _, _, err := cli.ImageInspectWithRaw(ctx, image); err != nil {
+		reader, err := cli.ImagePull(ctx, image, types.ImagePullOptions{})
+		if err != nil {
+			return "", err
+		}
+		_, _ = io.Copy(ioutil.Discard, reader)
+		reader.Close()
+	}
+
+	resp, err := cli.ContainerCreate(ctx, &container.Config{
+		Image:      image,
+		Entrypoint: []string{"cat"},
+		Cmd:        []string{file},
+	}, nil, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = cli.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+	}()
+
+	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return "", err
+	}
+
+	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return "", err
+		}
+	case status := <-statusCh:
+		if status.StatusCode != 0 {
+			return "", fmt.Errorf("unable to read %s from %s, exit code %d", file, image, status.StatusCode)
+		}
+	}
+
+	logs, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true})
+	if err != nil {
+		return "", err
+	}
+	defer logs.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&outBuf, &errBuf, logs); err != nil {
+		return "", err
+	}
+
+	return outBuf.String(), nil
+}
+
+// containerIP returns the ip of a running container in its first docker network
+func containerIP(name string) (string, error) {
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	containerJSON, err := cli.ContainerInspect(context.Background(), name)
+	if err != nil {
+		return "", err
+	}
+
+	for _, network := range containerJSON.NetworkSettings.Networks {
+		if network.IPAddress != "" {
+			return network.IPAddress, nil
+		}
+	}
+
+	return "", fmt.Errorf("container %s has no ip address", name)
+}
+
+// execContainer runs a command in a running container and returns its stdout
+func execContainer(name string, cmd []string) (string, error) {
+	ctx := context.Background()
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return "", err
+	}
+	defer cli.Close()
+
+	exec, err := cli.ContainerExecCreate(ctx, name, types.ExecConfig{
+		AttachStderr: true,
+		AttachStdout: true,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := cli.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Close()
+
+	var outBuf, errBuf bytes.Buffer
+	if _, err := stdcopy.StdCopy(&outBuf, &errBuf, resp.Reader); err != nil {
+		return "", err
+	}
+
+	res, err := cli.ContainerExecInspect(ctx, exec.ID)
+	if err != nil {
+		return "", err
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("%s in %s exited with %d: %s", strings.Join(cmd, " "), name, res.ExitCode, errBuf.String())
+	}
+
+	return outBuf.String(), nil
+}
+
+// bpmContainers returns all containers created by bpm on this host, running or not
+func bpmContainers() ([]types.ContainerJSON, error) {
+	ctx := context.Background()
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	list, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		All:     true,
+		Filters: filters.NewArgs(filters.Arg("name", "bpm-")),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var containers []types.ContainerJSON
+	for _, container := range list {
+		containerJSON, err := cli.ContainerInspect(ctx, container.ID)
+		if err != nil {
+			return nil, err
+		}
+		containers = append(containers, containerJSON)
+	}
+
+	return containers, nil
+}
+
+// runTools runs a command of the tools image with dir mounted as `/root/.celo`
+func (c *Celo) runTools(dir string, cmd ...string) (string, error) {
+
+	bm, err := docker.NewBasicManager(c.n)
+	if err != nil {
+		return "", err
+	}
+
+	container := docker.Container{
+		Name:  "celotools",
+		Image: c.imageTools,
+		Cmd:   cmd,
+		Mounts: []docker.Mount{
+			{
+				Type: "bind",
+				From: dir,
+				To:   "/root/.celo",
+			},
+		},
+		CollectLogs: false,
+	}
+
+	return bm.RunTransientContainer(context.Background(), container)
+}
+
+// gethIPC calls a json rpc method over the `geth.ipc` of a running container, found in the host
+// directory docker mounts as its datadir `/root/.celo`
+func gethIPC(containerName string, method string, result interface{}, params ...interface{}) error {
+
+	cli, err := client.NewEnvClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	containerJSON, err := cli.ContainerInspect(context.Background(), containerName)
+	if err != nil {
+		return err
+	}
+
+	var ipcPath string
+	for _, mount := range containerJSON.Mounts {
+		if mount.Destination == "/root/.celo" {
+			ipcPath = filepath.Join(mount.Source, "geth.ipc")
+		}
+	}
+	if ipcPath == "" {
+		return fmt.Errorf("container %s has no datadir mounted on /root/.celo", containerName)
+	}
+
+	conn, err := net.DialTimeout("unix", ipcPath, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(30 * time.Second)); err != nil {
+		return err
+	}
+
+	if params == nil {
+		params = []interface{}{}
+	}
+	request := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params}
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return err
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("%s: %s", method, response.Error.Message)
+	}
+
+	return json.Unmarshal(response.Result, result)
+}
+
+// gethExec evaluates javascript in the geth console of a running container
+func gethExec(containerName string, js string) (string, error) {
+	out, err := execContainer(containerName, []string{"geth", "--exec", js, "attach"})
+	if err != nil {
+		return "", err
+	}
+
+	// the console prints javascript errors but still exits with 0
+	if strings.HasPrefix(strings.TrimSpace(out), "Error") {
+		return "", fmt.Errorf("%s", strings.TrimSpace(out))
+	}
+
+	return out, nil
+}
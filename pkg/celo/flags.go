@@ -0,0 +1,129 @@
+package celo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// flag a single geth flag from the `celo` parameter
+type flag struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+func (f flag) String() string {
+	if f.hasValue {
+		return "--" + f.name + "=" + f.value
+	}
+	return "--" + f.name
+}
+
+// templateFlagRegexp matches the flag of a template line, including `{{ if ... }}--nousb{{ end }}`
+var templateFlagRegexp = regexp.MustCompile(`^(?:\{\{[^}]*\}\})?--?([A-Za-z0-9._-]+)`)
+
+// parseFlags parses `--name=value`, `--name value` and `--name` flags separated by spaces.
+// Only `--` starts a flag, so a value may start with a single dash, eg `--cache -1`.
+func parseFlags(s string) ([]flag, error) {
+	var flags []flag
+
+	tokens := strings.Fields(s)
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		if !strings.HasPrefix(token, "--") {
+			return nil, fmt.Errorf("%q is not a flag, flags start with --", token)
+		}
+		if strings.Contains(token, "{{") {
+			return nil, fmt.Errorf("%q must not contain templates", token)
+		}
+
+		f := flag{name: strings.TrimPrefix(token, "--")}
+		if parts := strings.SplitN(f.name, "=", 2); len(parts) == 2 {
+			f.name, f.value, f.hasValue = parts[0], parts[1], true
+		} else if i+1 < len(tokens) && !strings.HasPrefix(tokens[i+1], "--") {
+			f.value, f.hasValue = tokens[i+1], true
+			i++
+		}
+		if f.name == "" || strings.HasPrefix(f.name, "-") {
+			return nil, fmt.Errorf("%q is not a flag", token)
+		}
+
+		flags = append(flags, f)
+	}
+
+	return flags, nil
+}
+
+// mergeFlags appends flags to a docker command template. Flags the template or the config
+// already sets are rejected, or replace the ones of the template if override is set.
+// Geth applies flags after `config.toml`, so an overriding flag also replaces the config setting.
+func mergeFlags(tpl string, flags []flag, configFlags map[string]bool, override bool) (string, error) {
+	byName := map[string]flag{}
+	for _, f := range flags {
+		if _, ok := byName[f.name]; ok {
+			return "", fmt.Errorf("--%s is set more than once", f.name)
+		}
+		if configFlags[f.name] && !override {
+			return "", fmt.Errorf("--%s is already set by the plugin in config.toml, set celo_override to replace it", f.name)
+		}
+		byName[f.name] = f
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(tpl, "\n"), "\n") {
+		match := templateFlagRegexp.FindStringSubmatch(line)
+		if match != nil {
+			if _, ok := byName[match[1]]; ok {
+				if !override {
+					return "", fmt.Errorf("--%s is already set by the plugin, set celo_override to replace it", match[1])
+				}
+				continue
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	for _, f := range flags {
+		lines = append(lines, f.String())
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// checkDuplicateFlags fails if a flag is set more than once in a docker command,
+// or is set in the command as well as through its setting in `config.toml`
+func checkDuplicateFlags(cmd string, configFlags map[string]bool) error {
+	seen := map[string]bool{}
+	for _, line := range strings.Split(cmd, "\n") {
+		match := templateFlagRegexp.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		name := match[1]
+		if seen[name] {
+			return fmt.Errorf("--%s is set more than once", name)
+		}
+		if configFlags[name] {
+			return fmt.Errorf("--%s is also set in config.toml", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateCeloFlags checks that the `celo` flags can be merged into the docker command
+func (c *Celo) validateCeloFlags(config chainConfig) error {
+	flags, err := parseFlags(config.Celo)
+	if err != nil {
+		return err
+	}
+
+	dockerCmd, err := renderTemplate(c.cmdFile, c.kind.DockerCmdTpl(), c.templateData())
+	if err != nil {
+		return err
+	}
+
+	_, err = mergeFlags(dockerCmd, flags, c.gethConfigFlags(), config.CeloOverride)
+	return err
+}
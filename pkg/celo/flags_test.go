@@ -0,0 +1,104 @@
+package celo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []flag
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"equals", "--cache=5", []flag{{"cache", "5", true}}, false},
+		{"value after space", "--cache 5", []flag{{"cache", "5", true}}, false},
+		{"without value", "--nousb", []flag{{"nousb", "", false}}, false},
+		{"value with equals", "--vmodule=p2p=5", []flag{{"vmodule", "p2p=5", true}}, false},
+		{"negative value", "--cache -1", []flag{{"cache", "-1", true}}, false},
+		{"several", " --nousb  --cache 5\t--light.serve=90 ", []flag{{"nousb", "", false}, {"cache", "5", true}, {"light.serve", "90", true}}, false},
+		{"flag after flag without value", "--nousb --cache=5", []flag{{"nousb", "", false}, {"cache", "5", true}}, false},
+		{"value without flag", "cache", nil, true},
+		{"single dash", "-cache 5", nil, true},
+		{"second value", "--cache 5 6", nil, true},
+		{"empty name", "--", nil, true},
+		{"empty name with value", "--=5", nil, true},
+		{"triple dash", "---cache", nil, true},
+		{"template", "--etherbase={{ .Config.Signer }}", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFlags(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlags(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFlags(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeFlags(t *testing.T) {
+	tpl := "--config=/root/config.toml\n--verbosity=3\n{{ if .Config.NoUSB }}--nousb{{ end }}\n"
+
+	tests := []struct {
+		name        string
+		flags       []flag
+		configFlags map[string]bool
+		override    bool
+		want        string
+		wantErr     bool
+	}{
+		{"no flags", nil, nil, false, tpl, false},
+		{"new flag", []flag{{"cache", "5", true}}, nil, false, tpl + "--cache=5\n", false},
+		{"new flag without value", []flag{{"mine", "", false}}, nil, false, tpl + "--mine\n", false},
+		{"flag set twice", []flag{{"cache", "5", true}, {"cache", "6", true}}, nil, true, "", true},
+		{"flag of the template", []flag{{"verbosity", "5", true}}, nil, false, "", true},
+		{"flag of the template with override", []flag{{"verbosity", "5", true}}, nil, true, "--config=/root/config.toml\n{{ if .Config.NoUSB }}--nousb{{ end }}\n--verbosity=5\n", false},
+		{"conditional flag of the template with override", []flag{{"nousb", "", false}}, nil, true, "--config=/root/config.toml\n--verbosity=3\n--nousb\n", false},
+		{"flag of config.toml", []flag{{"maxpeers", "10", true}}, map[string]bool{"maxpeers": true}, false, "", true},
+		{"flag of config.toml with override", []flag{{"maxpeers", "10", true}}, map[string]bool{"maxpeers": true}, true, tpl + "--maxpeers=10\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mergeFlags(tpl, tt.flags, tt.configFlags, tt.override)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("mergeFlags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDuplicateFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		cmd         string
+		configFlags map[string]bool
+		wantErr     bool
+	}{
+		{"empty", "", nil, false},
+		{"unique", "--config=/root/config.toml\n--verbosity=3\n\n--nousb\n", nil, false},
+		{"set twice", "--verbosity=3\n--cache=5\n--verbosity=5\n", nil, true},
+		{"set twice with indent", "--verbosity=3\n  --verbosity=5\n", nil, true},
+		{"set twice in a condition", "{{ if .Config.NoUSB }}--nousb{{ end }}\n--nousb\n", nil, true},
+		{"single dash", "-verbosity=3\n-verbosity=5\n", nil, true},
+		{"set in config.toml", "--config=/root/config.toml\n--maxpeers=10\n", map[string]bool{"maxpeers": true}, true},
+		{"other flag in config.toml", "--config=/root/config.toml\n--cache=5\n", map[string]bool{"maxpeers": true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkDuplicateFlags(tt.cmd, tt.configFlags); (err != nil) != tt.wantErr {
+				t.Errorf("checkDuplicateFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package celo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// celoGenesis the parts of a genesis.json that make it a Celo genesis
+type celoGenesis struct {
+	Config *struct {
+		ChainID  json.Number      `json:"chainId"`
+		Istanbul *json.RawMessage `json:"istanbul"`
+	} `json:"config"`
+	ExtraData string                     `json:"extraData"`
+	Alloc     map[string]json.RawMessage `json:"alloc"`
+}
+
+// GenesisFile returns the absolute path of the `genesis-file` parameter, empty if not set
+func (c *Celo) GenesisFile() (string, error) {
+	chain := c.chain()
+	if chain == nil || chain.GenesisFile == "" {
+		return "", nil
+	}
+	file := chain.GenesisFile
+
+	// docker only bind mounts absolute paths
+	return filepath.Abs(file)
+}
+
+// ValidateGenesisFile checks that the `genesis-file` parameter is a Celo genesis for the network id
+func (c *Celo) ValidateGenesisFile() error {
+	file, err := c.GenesisFile()
+	if err != nil || file == "" {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("unable to read genesis file: %s", err)
+	}
+
+	var genesis celoGenesis
+	if err := json.Unmarshal(content, &genesis); err != nil {
+		return fmt.Errorf("genesis file %s is not valid json: %s", file, err)
+	}
+
+	if genesis.Config == nil || genesis.Config.Istanbul == nil {
+		return fmt.Errorf("genesis file %s has no istanbul config, not a Celo genesis", file)
+	}
+
+	// 32 bytes vanity followed by the rlp encoded istanbul extra
+	extra, err := hex.DecodeString(strings.TrimPrefix(genesis.ExtraData, "0x"))
+	if err != nil || len(extra) <= 32 {
+		return fmt.Errorf("genesis file %s has no istanbul extraData with the initial validators", file)
+	}
+
+	if networkID := c.chain().NetworkID; genesis.Config.ChainID.String() != networkID {
+		return fmt.Errorf("genesis file %s has chainId %q, expected networkid %s", file, genesis.Config.ChainID.String(), networkID)
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/node"
+)
+
+// LinkProxy writes the `enode`, `proxy_internal` and `proxy_external` of a running proxy into a validator node.
+// Without externalIP the ip the proxy advertises in its enode is used, if that is a public one.
+func LinkProxy(proxyFile string, validatorFile string, externalIP string) error {
+
+	proxy, err := node.Load(proxyFile)
+	if err != nil {
+		return fmt.Errorf("unable to load proxy node: %s", err)
+	}
+	if proxy.StrParameters["subtype"] != "proxy" {
+		return fmt.Errorf("%s is not a proxy but %q", proxyFile, proxy.StrParameters["subtype"])
+	}
+
+	validator, err := node.Load(validatorFile)
+	if err != nil {
+		return fmt.Errorf("unable to load validator node: %s", err)
+	}
+	if validator.StrParameters["subtype"] != "validator" {
+		return fmt.Errorf("%s is not a validator but %q", validatorFile, validator.StrParameters["subtype"])
+	}
+
+	// ipc, the admin api is not exposed over rpc by default
+	proxyContainer := "bpm-" + proxy.ID + "-proxy"
+	var nodeInfo struct {
+		Enode string `json:"enode"`
+	}
+	if err := gethIPC(proxyContainer, "admin_nodeInfo", &nodeInfo); err != nil {
+		return fmt.Errorf("unable to get proxy node info from %s: %s", proxyContainer, err)
+	}
+
+	enode, err := url.Parse(nodeInfo.Enode)
+	if err != nil || enode.User == nil {
+		return fmt.Errorf("proxy returned an invalid enode %q", nodeInfo.Enode)
+	}
+
+	internalIP, err := containerIP(proxyContainer)
+	if err != nil {
+		return fmt.Errorf("unable to get proxy ip: %s", err)
+	}
+
+	if externalIP == "" {
+		ip := net.ParseIP(enode.Hostname())
+		if ip == nil || ip.IsLoopback() || ip.IsUnspecified() || isPrivateIP(ip) {
+			return fmt.Errorf("proxy advertises %q, pass the external ip of the proxy host explicitly", enode.Hostname())
+		}
+		externalIP = ip.String()
+	}
+	if err := validateIP(externalIP); err != nil {
+		return err
+	}
+	// the enode advertises the port the proxy listens on, `port` of the proxy
+	external := externalIP
+	if port := enode.Port(); port != "" && port != proxyExternalPort {
+		external = net.JoinHostPort(externalIP, port)
+	}
+
+	validator.StrParameters["enode"] = enode.User.Username()
+	validator.StrParameters["proxy_internal"] = internalIP
+	validator.StrParameters["proxy_external"] = external
+
+	if err := validator.Save(); err != nil {
+		return fmt.Errorf("unable to save validator node: %s", err)
+	}
+
+	log.Printf("Linked validator %s to proxy %s: enode=%s proxy_internal=%s proxy_external=%s\n", validator.ID, proxy.ID, enode.User.Username(), internalIP, external)
+
+	return nil
+}
+
+func isPrivateIP(ip net.IP) bool {
+	for _, cidr := range []string{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"} {
+		_, network, _ := net.ParseCIDR(cidr)
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,152 @@
+package celo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// NetworkProfile the images, network id and bootnodes of a Celo network
+type NetworkProfile struct {
+	Image            string   `json:"image" yaml:"image"`
+	AttestationImage string   `json:"attestation-image" yaml:"attestation-image"`
+	ToolsImage       string   `json:"tools-image" yaml:"tools-image"`
+	NetworkID        string   `json:"networkid" yaml:"networkid"`
+	GenesisHash      string   `json:"genesis-hash" yaml:"genesis-hash"`
+	Bootnodes        []string `json:"bootnodes" yaml:"bootnodes"`
+
+	file string // the network file the profile is defined or overridden in, empty for a built-in profile
+}
+
+// defaultNetworks the built-in network profiles. A profile without bootnodes
+// uses the ones shipped in `/celo/bootnodes` of its image, one without a genesis
+// hash is not checked after `geth init`. The tools image (`geth-all`, with
+// `bootnode`) runs bootnodes and generates devnet keys.
+var defaultNetworks = map[string]NetworkProfile{
+	"mainnet": {
+		Image:            "us.gcr.io/celo-org/celo-node:mainnet",
+		AttestationImage: "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4",
+		ToolsImage:       "us.gcr.io/celo-org/geth-all:1.5.0",
+		NetworkID:        "42220",
+		GenesisHash:      "0x19ea3339d3c8cda97235bc8293240d5b9dadcdfbb5d4b103a7a32ad1a2a2c7eb",
+		Bootnodes: []string{
+			"enode://5c9a3afb564b48cc2fa2e06b76d0c5d8f6910e1930ea7d0930213a0cbc20450434cd442f6483688eff436ad14dc29cb90c9592cc5c1d27ca62f28d4d8475d932@34.82.79.155:30301",
+			"enode://2874c2abd970a043e9aae6ef1f07521f747776d38c8bd907b9e0c08d6b19c606e2f46c0539d829bc79e4053a2f53a0348b89ab35cb179748e157ef8c87acf120@34.75.29.120:30303",
+		},
+	},
+	"baklava": {
+		Image:            "us.gcr.io/celo-testnet/celo-node:baklava",
+		AttestationImage: "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4",
+		ToolsImage:       "us.gcr.io/celo-org/geth-all:1.5.0",
+		NetworkID:        "62320",
+		// no genesis hash, baklava is reset with a new genesis from time to time
+		Bootnodes: []string{
+			"enode://5aaf10664b12431c250597e980aacd7d5373cae00f128be5b00364344bb96bce7555b50973664bddebd1cb7a6d3fb927bec81527f80e22a26fa373c375fcdefc@35.247.103.141:30301",
+		},
+	},
+	"alfajores": {
+		Image:            "us.gcr.io/celo-org/celo-node:alfajores",
+		AttestationImage: "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4",
+		ToolsImage:       "us.gcr.io/celo-org/geth-all:1.5.0",
+		NetworkID:        "44787",
+		GenesisHash:      "0xe423b034e7f0282c1b621f7bbc1cea4316a2a80b1600490769eae77777e4b67e",
+	},
+	"devnet": {
+		Image:            "us.gcr.io/celo-org/geth:1.5.0",
+		AttestationImage: "us.gcr.io/celo-testnet/celo-monorepo:attestation-service-1-0-4",
+		ToolsImage:       "us.gcr.io/celo-org/geth-all:1.5.0",
+		NetworkID:        "1101",
+	},
+}
+
+// LoadNetworks returns the built-in network profiles, overridden by the profiles in file.
+// The file is YAML or JSON (by extension) mapping network names to profiles, only
+// the fields that are set replace the built-in ones.
+func LoadNetworks(file string) (map[string]NetworkProfile, error) {
+	networks := map[string]NetworkProfile{}
+	for name, profile := range defaultNetworks {
+		networks[name] = profile
+	}
+
+	if file == "" {
+		return networks, nil
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read network file: %s", err)
+	}
+
+	var overrides map[string]NetworkProfile
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&overrides)
+	default:
+		err = yaml.UnmarshalStrict(content, &overrides)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse network file %s: %s", file, err)
+	}
+
+	for name, override := range overrides {
+		profile := networks[name]
+		if override.Image != "" {
+			profile.Image = override.Image
+		}
+		if override.AttestationImage != "" {
+			profile.AttestationImage = override.AttestationImage
+		}
+		if override.ToolsImage != "" {
+			profile.ToolsImage = override.ToolsImage
+		}
+		if override.NetworkID != "" {
+			profile.NetworkID = override.NetworkID
+		}
+		if override.GenesisHash != "" {
+			profile.GenesisHash = override.GenesisHash
+		}
+		if len(override.Bootnodes) > 0 {
+			profile.Bootnodes = override.Bootnodes
+		}
+
+		if profile.Image == "" || profile.NetworkID == "" {
+			return nil, fmt.Errorf("network %q in %s needs at least an image and a networkid", name, file)
+		}
+		profile.file = file
+		networks[name] = profile
+	}
+
+	return networks, nil
+}
+
+// networkNames returns the sorted names of all networks
+func networkNames(networks map[string]NetworkProfile) []string {
+	var names []string
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// staticNodes renders a `static-nodes.json` with the given enode urls
+func staticNodes(enodes []string) string {
+	nodes := []string{}
+	for _, enode := range enodes {
+		if enode = strings.TrimSpace(enode); enode != "" {
+			nodes = append(nodes, enode)
+		}
+	}
+
+	content, _ := json.MarshalIndent(nodes, "", "  ")
+	return string(content)
+}
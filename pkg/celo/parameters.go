@@ -0,0 +1,625 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// parameterSet all parameters of the plugin, subtypes pick theirs and adjust mandatory and defaults on the copy
+type parameterSet struct {
+	subtype          plugin.Parameter
+	network          plugin.Parameter
+	networkFile      plugin.Parameter
+	networkID        plugin.Parameter
+	signer           plugin.Parameter
+	noUSB            plugin.Parameter
+	validator        plugin.Parameter
+	genesisFile      plugin.Parameter
+	bootnodes        plugin.Parameter
+	keystore         plugin.Parameter
+	keypass          plugin.Parameter
+	port             plugin.Parameter
+	proxyInternal    plugin.Parameter
+	proxyExternal    plugin.Parameter
+	enode            plugin.Parameter
+	proxies          plugin.Parameter
+	rpcaddr          plugin.Parameter
+	rpcPort          plugin.Parameter
+	rpcAPI           plugin.Parameter
+	rpcCorsDomain    plugin.Parameter
+	ws               plugin.Parameter
+	wsAddr           plugin.Parameter
+	wsPort           plugin.Parameter
+	wsAPI            plugin.Parameter
+	lightServe       plugin.Parameter
+	lightMaxpeers    plugin.Parameter
+	maxpeers         plugin.Parameter
+	validators       plugin.Parameter
+	cache            plugin.Parameter
+	syncMode         plugin.Parameter
+	lightServers     plugin.Parameter
+	externalIP       plugin.Parameter
+	replica          plugin.Parameter
+	verbosity        plugin.Parameter
+	vmodule          plugin.Parameter
+	account          plugin.Parameter
+	celoCommands     plugin.Parameter
+	celoOverride     plugin.Parameter
+	configToml       plugin.Parameter
+	database         plugin.Parameter
+	dbSSLMode        plugin.Parameter
+	dbCAFile         plugin.Parameter
+	dbPassword       plugin.Parameter
+	dbUser           plugin.Parameter
+	postgresImage    plugin.Parameter
+	postgresVersion  plugin.Parameter
+	attNode          plugin.Parameter
+	smsProviders     plugin.Parameter
+	smsCountries     plugin.Parameter
+	twilioServiceSID plugin.Parameter
+	twilioAccountSID plugin.Parameter
+	twilioBlacklist  plugin.Parameter
+	twilioAuthToken  plugin.Parameter
+	nexmoKey         plugin.Parameter
+	nexmoSecret      plugin.Parameter
+	nexmoBlacklist   plugin.Parameter
+	messageBirdKey   plugin.Parameter
+}
+
+func (c *Celo) parameterSet() parameterSet {
+	var p parameterSet
+
+	p.subtype = plugin.Parameter{
+		Name:        "subtype",
+		Type:        plugin.ParameterTypeString,
+		Description: "The type of node. Must be either `validator`, `proxy`, `fullnode`, `archive`, `light`, `bootnode`, `attestation-node`, `attestation-service` or `devnet`",
+		Mandatory:   false,
+		Default:     "fullnode",
+	}
+	p.network = plugin.Parameter{
+		Name:        "network",
+		Type:        plugin.ParameterTypeString,
+		Description: "Mainnet, baklava or alfajores testnet, or a network from `network-file`",
+		Mandatory:   true,
+		Default:     "baklava",
+	}
+	p.networkFile = plugin.Parameter{
+		Name:        "network-file",
+		Type:        plugin.ParameterTypeString,
+		Description: "YAML or JSON file with network profiles (image, attestation-image, networkid, bootnodes) overriding or adding to the built-in networks",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.networkID = plugin.Parameter{
+		Name:        "networkid",
+		Type:        plugin.ParameterTypeString,
+		Description: "The current Celo network id",
+		Mandatory:   true,
+		Default:     c.networkID,
+	}
+	p.signer = plugin.Parameter{
+		Name:        "signer",
+		Type:        plugin.ParameterTypeString,
+		Description: "The signer address",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.noUSB = plugin.Parameter{
+		Name:        "nousb",
+		Type:        plugin.ParameterTypeString,
+		Description: "Boolean. Wether to expect usb connections, eg ledger",
+		Mandatory:   false,
+		Default:     "true",
+	}
+	p.validator = plugin.Parameter{
+		Name:        "validator",
+		Type:        plugin.ParameterTypeString,
+		Description: "The validator address",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.genesisFile = plugin.Parameter{
+		Name:        "genesis-file",
+		Type:        plugin.ParameterTypeString,
+		Description: "Genesis json on the host to init the chain with instead of the one in the image, its chainId must match `networkid`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.bootnodes = plugin.Parameter{
+		Name:        "bootnodes",
+		Type:        plugin.ParameterTypeString,
+		Description: "List of bootnodes to connect to",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.keystore = plugin.Parameter{
+		Name:        "keystore-file",
+		Type:        plugin.ParameterTypeString,
+		Description: "Location of the signer keystore json",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.keypass = plugin.Parameter{
+		Name:        "keystore-pass",
+		Type:        plugin.ParameterTypeString,
+		Description: "The password for the keystore json",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.port = plugin.Parameter{
+		Name:        "port",
+		Type:        plugin.ParameterTypeString,
+		Description: "Port to listen to",
+		Mandatory:   false,
+		Default:     "30303",
+	}
+	p.proxyInternal = plugin.Parameter{
+		Name:        "proxy_internal",
+		Type:        plugin.ParameterTypeString,
+		Description: "The internal proxy ip, use external if none",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.proxyExternal = plugin.Parameter{
+		Name:        "proxy_external",
+		Type:        plugin.ParameterTypeString,
+		Description: "The external proxy ip, with :<port> if the proxy does not listen on 30303",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.enode = plugin.Parameter{
+		Name:        "enode",
+		Type:        plugin.ParameterTypeString,
+		Description: "The proxy enode id",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.proxies = plugin.Parameter{
+		Name:        "proxies",
+		Type:        plugin.ParameterTypeString,
+		Description: "Additional proxies, comma separated `<enode id>@<internal ip>;<external ip>`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.rpcaddr = plugin.Parameter{
+		Name:        "rpcaddr",
+		Type:        plugin.ParameterTypeString,
+		Description: "The rpcaddr ip address",
+		Mandatory:   false,
+		Default:     "0.0.0.0",
+	}
+	p.rpcPort = plugin.Parameter{
+		Name:        "rpcport",
+		Type:        plugin.ParameterTypeString,
+		Description: "The rpc port for the hostt",
+		Mandatory:   false,
+		Default:     "8545",
+	}
+	p.rpcAPI = plugin.Parameter{
+		Name:        "rpcapi",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated APIs served over rpc, admin and personal should not be exposed publicly",
+		Mandatory:   false,
+		Default:     "eth,net,web3",
+	}
+	p.rpcCorsDomain = plugin.Parameter{
+		Name:        "rpccorsdomain",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated domains from which to accept cross origin rpc requests",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.ws = plugin.Parameter{
+		Name:        "ws",
+		Type:        plugin.ParameterTypeString,
+		Description: "Boolean. Wether to serve the websocket rpc endpoint",
+		Mandatory:   false,
+		Default:     "false",
+	}
+	p.wsAddr = plugin.Parameter{
+		Name:        "wsaddr",
+		Type:        plugin.ParameterTypeString,
+		Description: "The websocket ip address",
+		Mandatory:   false,
+		Default:     "0.0.0.0",
+	}
+	p.wsPort = plugin.Parameter{
+		Name:        "wsport",
+		Type:        plugin.ParameterTypeString,
+		Description: "The websocket port for the host",
+		Mandatory:   false,
+		Default:     "8546",
+	}
+	p.wsAPI = plugin.Parameter{
+		Name:        "wsapi",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated APIs served over websocket",
+		Mandatory:   false,
+		Default:     "eth,net,web3",
+	}
+	p.lightServe = plugin.Parameter{
+		Name:        "light_serve",
+		Type:        plugin.ParameterTypeString,
+		Description: "light.serve",
+		Mandatory:   false,
+		Default:     "90",
+	}
+	p.lightMaxpeers = plugin.Parameter{
+		Name:        "light_maxpeers",
+		Type:        plugin.ParameterTypeString,
+		Description: "The max peers `light.maxpeers`",
+		Mandatory:   false,
+		Default:     "1000",
+	}
+	p.maxpeers = plugin.Parameter{
+		Name:        "maxpeers",
+		Type:        plugin.ParameterTypeString,
+		Description: "The max peers of geth `--maxpeers`, `Node.P2P.MaxPeers` in config.toml",
+		Mandatory:   false,
+		Default:     "1100",
+	}
+	p.validators = plugin.Parameter{
+		Name:        "validators",
+		Type:        plugin.ParameterTypeString,
+		Description: "Number of mining validators in a devnet",
+		Mandatory:   false,
+		Default:     "1",
+	}
+	p.cache = plugin.Parameter{
+		Name:        "cache",
+		Type:        plugin.ParameterTypeString,
+		Description: "Megabytes of memory allocated to internal caching",
+		Mandatory:   false,
+		Default:     "4096",
+	}
+	p.syncMode = plugin.Parameter{
+		Name:        "syncmode",
+		Type:        plugin.ParameterTypeString,
+		Description: "Sync mode of a light client, `light` or `lightest`",
+		Mandatory:   false,
+		Default:     "lightest",
+	}
+	p.lightServers = plugin.Parameter{
+		Name:        "light_servers",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated enode urls of fullnodes running with `light.serve` that a light client always connects to",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.externalIP = plugin.Parameter{
+		Name:        "external_ip",
+		Type:        plugin.ParameterTypeString,
+		Description: "The public ip a bootnode advertises in its enode url",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.replica = plugin.Parameter{
+		Name:        "replica",
+		Type:        plugin.ParameterTypeString,
+		Description: "Boolean. Run the validator as replica (`istanbul.replica`) that takes over with `swap-validator`",
+		Mandatory:   false,
+		Default:     "false",
+	}
+	p.verbosity = plugin.Parameter{
+		Name:        "verbosity",
+		Type:        plugin.ParameterTypeString,
+		Description: "Log level from 0 (silent) to 5 (detail)",
+		Mandatory:   false,
+		Default:     "3",
+	}
+	p.vmodule = plugin.Parameter{
+		Name:        "vmodule",
+		Type:        plugin.ParameterTypeString,
+		Description: "Per module log level, eg `p2p=5,istanbul/*=4`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.account = plugin.Parameter{
+		Name:        "account",
+		Type:        plugin.ParameterTypeString,
+		Description: "The account to send rewards to",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.celoCommands = plugin.Parameter{
+		Name:        "celo",
+		Type:        plugin.ParameterTypeString,
+		Description: "Extra flags for geth. Example: `--celo=\"--txpool.pricelimit=1000 --cache 2048 --gcmode=archive\"`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.celoOverride = plugin.Parameter{
+		Name:        "celo_override",
+		Type:        plugin.ParameterTypeString,
+		Description: "Boolean. Wether flags in `celo` may replace the ones set by the plugin instead of being rejected",
+		Mandatory:   false,
+		Default:     "false",
+	}
+	p.configToml = plugin.Parameter{
+		Name:        "config_toml",
+		Type:        plugin.ParameterTypeString,
+		Description: "Partial geth toml file merged over the generated `config.toml`, its settings take precedence",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.database = plugin.Parameter{
+		Name:        "database",
+		Type:        plugin.ParameterTypeString,
+		Description: "URL of an external postgres database for attestation service, eg postgres://db.example.com:5432/attestation. No postgres container is run when set",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.dbSSLMode = plugin.Parameter{
+		Name:        "db_sslmode",
+		Type:        plugin.ParameterTypeString,
+		Description: "`sslmode` of the attestation service database connection: disable, allow, prefer, require, verify-ca or verify-full",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.dbCAFile = plugin.Parameter{
+		Name:        "db_ca_file",
+		Type:        plugin.ParameterTypeString,
+		Description: "CA certificate file to verify the attestation service database with",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.dbPassword = plugin.Parameter{
+		Name:        "db_password",
+		Type:        plugin.ParameterTypeString,
+		Description: "Database password for attestation service postgres, mandatory without `database`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.dbUser = plugin.Parameter{
+		Name:        "db_user",
+		Type:        plugin.ParameterTypeString,
+		Description: "Database user for attestation service postgres, mandatory without `database`",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.postgresImage = plugin.Parameter{
+		Name:        "postgres_image",
+		Type:        plugin.ParameterTypeString,
+		Description: "Postgres image for attestation service, without tag",
+		Mandatory:   false,
+		Default:     "docker.io/library/postgres",
+	}
+	p.postgresVersion = plugin.Parameter{
+		Name:        "postgres_version",
+		Type:        plugin.ParameterTypeString,
+		Description: "Tag of `postgres_image`, its major version must match the one that created the existing database",
+		Mandatory:   false,
+		Default:     "13",
+	}
+	p.attNode = plugin.Parameter{
+		Name:        "node_url",
+		Type:        plugin.ParameterTypeString,
+		Description: "Attestation node url, eg http://bpm-flower-pot-1234-attestattion-node:8545",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.smsProviders = plugin.Parameter{
+		Name:        "sms_providers",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated SMS providers of the attestation service in order of preference: twilio, nexmo or messagebird",
+		Mandatory:   false,
+		Default:     "twilio",
+	}
+	p.smsCountries = plugin.Parameter{
+		Name:        "sms_country_providers",
+		Type:        plugin.ParameterTypeString,
+		Description: "Comma separated SMS providers per country, in the format `<country code>=<provider>;<provider>`, eg MX=messagebird;twilio",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.twilioServiceSID = plugin.Parameter{
+		Name:        "twilio_service_sid",
+		Type:        plugin.ParameterTypeString,
+		Description: "Twilio messaging service SID for attestation services",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.twilioAccountSID = plugin.Parameter{
+		Name:        "twilio_account_sid",
+		Type:        plugin.ParameterTypeString,
+		Description: "Twilio account SID for attesation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.twilioBlacklist = plugin.Parameter{
+		Name:        "twilio_blacklist",
+		Type:        plugin.ParameterTypeString,
+		Description: "Twilio blacklist for attesation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.twilioAuthToken = plugin.Parameter{
+		Name:        "twilio_auth_token",
+		Type:        plugin.ParameterTypeString,
+		Description: "Auth token for Twilio",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.nexmoKey = plugin.Parameter{
+		Name:        "nexmo_key",
+		Type:        plugin.ParameterTypeString,
+		Description: "Nexmo (Vonage) API key for attestation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.nexmoSecret = plugin.Parameter{
+		Name:        "nexmo_secret",
+		Type:        plugin.ParameterTypeString,
+		Description: "Nexmo (Vonage) API secret for attestation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.nexmoBlacklist = plugin.Parameter{
+		Name:        "nexmo_blacklist",
+		Type:        plugin.ParameterTypeString,
+		Description: "Nexmo (Vonage) blacklist for attestation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+	p.messageBirdKey = plugin.Parameter{
+		Name:        "messagebird_api_key",
+		Type:        plugin.ParameterTypeString,
+		Description: "MessageBird API key for attestation service",
+		Mandatory:   false,
+		Default:     "",
+	}
+
+	return p
+}
+
+// subtypeConfig the typed parameters of node.json of a subtype, filled by decodeConfig. Every config embeds
+// commonConfig and the sections it shares with other subtypes, so it only has fields for its own parameters.
+// Numbers and booleans that fail to parse stay empty, Validate reports them.
+type subtypeConfig interface {
+	common() commonConfig
+}
+
+// commonConfig the parameters of every subtype
+type commonConfig struct {
+	Subtype     string `param:"subtype"`
+	Network     string `param:"network"`
+	NetworkFile string `param:"network-file"`
+	DataDir     string `param:"data-dir"`
+}
+
+func (config commonConfig) common() commonConfig {
+	return config
+}
+
+// logConfig the log settings of geth and bootnode
+type logConfig struct {
+	Verbosity int    `param:"verbosity"`
+	Vmodule   string `param:"vmodule"`
+}
+
+// chainConfig the parameters of every subtype running geth on the chain of the network
+type chainConfig struct {
+	NetworkID    string `param:"networkid"`
+	GenesisFile  string `param:"genesis-file"`
+	Port         string `param:"port"`
+	Celo         string `param:"celo"`
+	CeloOverride bool   `param:"celo_override"`
+	ConfigToml   string `param:"config_toml"`
+}
+
+func (config *chainConfig) chain() *chainConfig {
+	return config
+}
+
+// rpcConfig json rpc and websockets
+type rpcConfig struct {
+	RPCAddr       string   `param:"rpcaddr"`
+	RPCPort       string   `param:"rpcport"`
+	RPCAPI        []string `param:"rpcapi"`
+	RPCCorsDomain []string `param:"rpccorsdomain"`
+	WS            bool     `param:"ws"`
+	WSAddr        string   `param:"wsaddr"`
+	WSPort        string   `param:"wsport"`
+	WSAPI         []string `param:"wsapi"`
+}
+
+// keystoreConfig the account of `signer` geth unlocks
+type keystoreConfig struct {
+	Signer       string `param:"signer"`
+	KeystoreFile string `param:"keystore-file"`
+	KeystorePass string `param:"keystore-pass"`
+}
+
+// commonParameters are decoded for every subtype, `data-dir` is set in node.json by bpm itself
+// and `subtype` selects the subtype even where it is not listed as parameter
+var commonParameters = []string{"data-dir", "subtype"}
+
+// parameterValue returns the value of a parameter in node.json, its default if it is not set.
+// The default of `networkid` is the network id of the network profile.
+func (c *Celo) parameterValue(p plugin.Parameter) string {
+	if value := c.n.StrParameters[p.Name]; value != "" {
+		return value
+	}
+	return p.Default
+}
+
+// decodeConfig decodes the parameters of the current subtype from node.json into the config of the subtype
+// and warns about keys that are not a parameter of the subtype
+func (c *Celo) decodeConfig() error {
+	values := map[string]string{}
+	for _, name := range commonParameters {
+		values[name] = c.n.StrParameters[name]
+	}
+	for _, p := range c.GetParameters() {
+		values[p.Name] = c.parameterValue(p)
+	}
+
+	// bpm writes the defaults of all parameters, those of other subtypes are only worth a warning when changed
+	defaults := map[string]string{}
+	for _, p := range (&metaSubtype{}).Parameters(c) {
+		defaults[p.Name] = p.Default
+	}
+
+	var names []string
+	for name := range c.n.StrParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		if value, ok := defaults[name]; !ok {
+			log.Printf("Warning: ignoring unknown parameter %q\n", name)
+		} else if c.n.StrParameters[name] != value {
+			log.Printf("Warning: ignoring %q, it is not a parameter of subtype %s\n", name, c.Subtype)
+		}
+	}
+
+	return decodeStruct(reflect.ValueOf(c.kind.Config()).Elem(), values, c.Subtype)
+}
+
+// decodeStruct sets the fields of a config and of its embedded sections from the parameter values.
+// A field of a parameter the subtype does not have is a bug, it would silently stay empty.
+func decodeStruct(v reflect.Value, values map[string]string, subtype string) error {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if v.Type().Field(i).Anonymous {
+			if err := decodeStruct(field, values, subtype); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := v.Type().Field(i).Tag.Get("param")
+		value, ok := values[name]
+		if !ok {
+			return fmt.Errorf("%s.%s decodes %q, which is not a parameter of subtype %s", v.Type().Name(), v.Type().Field(i).Name, name, subtype)
+		}
+		if value == "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			if n, err := strconv.Atoi(value); err == nil {
+				field.SetInt(int64(n))
+			}
+		case reflect.Bool:
+			if b, err := strconv.ParseBool(value); err == nil {
+				field.SetBool(b)
+			}
+		case reflect.Slice:
+			field.Set(reflect.ValueOf(splitList(value)))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package celo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+)
+
+// dbCAFile where the attestation service finds the CA certificate of `db_ca_file`
+const dbCAFile = "/root/db-ca.crt"
+
+// postgresVersionRegexp the major version at the start of a postgres image tag, eg `13` of `13.4-alpine`
+var postgresVersionRegexp = regexp.MustCompile(`^([0-9]+)(?:[.-][0-9A-Za-z._-]*)?$`)
+
+// postgresMajorVersion returns the major version of a postgres image tag, empty if the tag has none
+func postgresMajorVersion(tag string) string {
+	match := postgresVersionRegexp.FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// postgresDir the data directory of the attestation service database, inside the data dir
+func (c *Celo) postgresDir() string {
+	return filepath.Join(c.config.DataDir, "postgres")
+}
+
+func postgresImage(config attestationServiceConfig) string {
+	return config.PostgresImage + ":" + config.PostgresVersion
+}
+
+func (c *Celo) postgresMount() docker.Mount {
+	return docker.Mount{
+		Type: "bind",
+		From: c.postgresDir(),
+		To:   "/var/lib/postgresql/data",
+	}
+}
+
+// checkPostgresDir creates the database directory on the first start. Postgres refuses to open a
+// database created by another major version, so an existing one has to match `postgres_version`.
+func (c *Celo) checkPostgresDir(config attestationServiceConfig) error {
+	dir := c.postgresDir()
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "PG_VERSION"))
+	if os.IsNotExist(err) {
+		return os.MkdirAll(dir, 0700)
+	}
+	if err != nil {
+		return err
+	}
+
+	existing := strings.TrimSpace(string(content))
+	if expected := postgresMajorVersion(config.PostgresVersion); existing != expected {
+		return fmt.Errorf("the database in %s was created by postgres %s, postgres_version %s is major version %s: set postgres_version to %s or upgrade the database with pg_upgrade", dir, existing, config.PostgresVersion, expected, existing)
+	}
+
+	return nil
+}
+
+// getDBUrl returns the url the attestation service connects with, to the external database of `database`
+// or to the postgres container. `db_user` and `db_password` are escaped, each only replaces its part
+// of the credentials of `database` when set.
+func (c *Celo) getDBUrl(config attestationServiceConfig) string {
+	u := &url.URL{
+		Scheme: "postgres",
+		Host:   "bpm-" + c.n.ID + "-attestation-postgres:5432",
+	}
+	if config.Database != "" {
+		var err error
+		if u, err = url.Parse(config.Database); err != nil {
+			return config.Database // reported by Validate
+		}
+	}
+
+	user := u.User.Username()
+	password, hasPassword := u.User.Password()
+	if config.DBUser != "" {
+		user = config.DBUser
+	}
+	if config.DBPassword != "" {
+		password, hasPassword = config.DBPassword, true
+	}
+	if hasPassword {
+		u.User = url.UserPassword(user, password)
+	} else if user != "" {
+		u.User = url.User(user)
+	}
+
+	query := u.Query()
+	if config.DBSSLMode != "" {
+		query.Set("sslmode", config.DBSSLMode)
+	}
+	if config.DBCAFile != "" {
+		query.Set("sslrootcert", dbCAFile)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
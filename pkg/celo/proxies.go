@@ -0,0 +1,86 @@
+package celo
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// proxyExternalPort the p2p port of a proxy when its external endpoint is a bare ip
+const proxyExternalPort = "30303"
+
+// proxy a proxy of a validator, reached on internalIP:30503 and on external, `<ip>` or `<ip>:<port>`
+type proxy struct {
+	enode      string
+	internalIP string
+	external   string
+}
+
+// validatorProxies returns the proxy from `enode`, `proxy_internal` and `proxy_external`
+// followed by the ones in `proxies`, with a problem for every incomplete or invalid entry
+func (config validatorConfig) validatorProxies() ([]proxy, []string) {
+	var proxies []proxy
+	var problems []string
+
+	single := proxy{
+		enode:      config.Enode,
+		internalIP: config.ProxyInternal,
+		external:   config.ProxyExternal,
+	}
+	if single.enode != "" || single.internalIP != "" || single.external != "" {
+		if single.enode == "" || single.internalIP == "" || single.external == "" {
+			problems = append(problems, "enode, proxy_internal, proxy_external: must be set together")
+		} else {
+			proxies = append(proxies, single)
+		}
+	}
+
+	for i, entry := range config.Proxies {
+		// <enode id>@<internal ip>;<external ip>[:<port>]
+		p := proxy{}
+		enodeAndIPs := strings.SplitN(entry, "@", 2)
+		if len(enodeAndIPs) == 2 {
+			ips := strings.SplitN(enodeAndIPs[1], ";", 2)
+			p.enode = enodeAndIPs[0]
+			p.internalIP = ips[0]
+			if len(ips) == 2 {
+				p.external = ips[1]
+			}
+		}
+
+		if p.enode == "" || p.internalIP == "" || p.external == "" {
+			problems = append(problems, fmt.Sprintf("proxies: entry %d %q must be <enode id>@<internal ip>;<external ip>[:<port>]", i+1, entry))
+			continue
+		}
+		for _, err := range []error{validateNodeID(p.enode), validateIP(p.internalIP), validateProxyExternal(p.external)} {
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("proxies: entry %d: %s", i+1, err))
+			}
+		}
+		proxies = append(proxies, p)
+	}
+
+	if len(proxies) == 0 && len(problems) == 0 {
+		problems = append(problems, "enode, proxy_internal, proxy_external or proxies: a validator needs at least one proxy")
+	}
+
+	return proxies, problems
+}
+
+// proxyEnodeURLPairs renders the value of `--proxy.proxyenodeurlpairs`
+func proxyEnodeURLPairs(proxies []proxy) string {
+	var pairs []string
+	for _, p := range proxies {
+		ip, port, _ := splitProxyExternal(p.external)
+		pairs = append(pairs, "enode://"+p.enode+"@"+net.JoinHostPort(p.internalIP, "30503")+";enode://"+p.enode+"@"+net.JoinHostPort(ip, port))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// splitProxyExternal splits the external endpoint of a proxy into its ip and port, 30303 if it has none
+func splitProxyExternal(external string) (string, string, error) {
+	if net.ParseIP(external) != nil {
+		return external, proxyExternalPort, nil
+	}
+	return net.SplitHostPort(external)
+}
@@ -0,0 +1,94 @@
+package celo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidatorProxies(t *testing.T) {
+	enode1 := strings.Repeat("a1", 64)
+	enode2 := strings.Repeat("b2", 64)
+
+	tests := []struct {
+		name         string
+		config       validatorConfig
+		want         []proxy
+		wantProblems []string
+	}{
+		{
+			name:         "no proxy",
+			config:       validatorConfig{},
+			wantProblems: []string{"enode, proxy_internal, proxy_external or proxies: a validator needs at least one proxy"},
+		},
+		{
+			name:   "single proxy",
+			config: validatorConfig{Enode: enode1, ProxyInternal: "172.20.0.3", ProxyExternal: "1.2.3.4"},
+			want:   []proxy{{enode1, "172.20.0.3", "1.2.3.4"}},
+		},
+		{
+			name:         "single proxy incomplete",
+			config:       validatorConfig{Enode: enode1, ProxyExternal: "1.2.3.4"},
+			wantProblems: []string{"enode, proxy_internal, proxy_external: must be set together"},
+		},
+		{
+			name:   "single proxy and proxies",
+			config: validatorConfig{Enode: enode1, ProxyInternal: "172.20.0.3", ProxyExternal: "1.2.3.4", Proxies: []string{enode2 + "@172.20.0.4;5.6.7.8:30304"}},
+			want:   []proxy{{enode1, "172.20.0.3", "1.2.3.4"}, {enode2, "172.20.0.4", "5.6.7.8:30304"}},
+		},
+		{
+			name:         "entry without external ip",
+			config:       validatorConfig{Proxies: []string{enode1 + "@172.20.0.3"}},
+			wantProblems: []string{`proxies: entry 1 "` + enode1 + `@172.20.0.3" must be <enode id>@<internal ip>;<external ip>[:<port>]`},
+		},
+		{
+			name:         "entry without enode",
+			config:       validatorConfig{Proxies: []string{"172.20.0.3;1.2.3.4"}},
+			wantProblems: []string{`proxies: entry 1 "172.20.0.3;1.2.3.4" must be <enode id>@<internal ip>;<external ip>[:<port>]`},
+		},
+		{
+			name:   "entry with invalid values",
+			config: validatorConfig{Proxies: []string{"abc@172.20.0;1.2.3.4:0"}},
+			want:   []proxy{{"abc", "172.20.0", "1.2.3.4:0"}},
+			wantProblems: []string{
+				`proxies: entry 1: "abc" is not an enode id of 128 hex characters`,
+				`proxies: entry 1: "172.20.0" is not an ip address`,
+				`proxies: entry 1: "1.2.3.4:0" is not <ip> or <ip>:<port>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, problems := tt.config.validatorProxies()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validatorProxies() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(problems, tt.wantProblems) {
+				t.Errorf("validatorProxies() problems = %q, want %q", problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestProxyEnodeURLPairs(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []proxy
+		want    string
+	}{
+		{"none", nil, ""},
+		{"external ip", []proxy{{"a1", "172.20.0.3", "1.2.3.4"}}, "enode://a1@172.20.0.3:30503;enode://a1@1.2.3.4:30303"},
+		{"external ip and port", []proxy{{"a1", "172.20.0.3", "1.2.3.4:30304"}}, "enode://a1@172.20.0.3:30503;enode://a1@1.2.3.4:30304"},
+		{"ipv6", []proxy{{"a1", "fd00::3", "2001:db8::1"}}, "enode://a1@[fd00::3]:30503;enode://a1@[2001:db8::1]:30303"},
+		{"several", []proxy{{"a1", "172.20.0.3", "1.2.3.4"}, {"b2", "172.20.0.4", "[2001:db8::1]:30304"}}, "enode://a1@172.20.0.3:30503;enode://a1@1.2.3.4:30303,enode://b2@172.20.0.4:30503;enode://b2@[2001:db8::1]:30304"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := proxyEnodeURLPairs(tt.proxies); got != tt.want {
+				t.Errorf("proxyEnodeURLPairs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package celo
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// smsProviderCredentials the parameters every SMS provider of the attestation service needs
+var smsProviderCredentials = map[string][]string{
+	"twilio":      {"twilio_service_sid", "twilio_account_sid", "twilio_auth_token"},
+	"nexmo":       {"nexmo_key", "nexmo_secret"},
+	"messagebird": {"messagebird_api_key"},
+}
+
+// countryCodeRegexp an ISO 3166-1 alpha-2 country code, eg MX
+var countryCodeRegexp = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// smsData the SMS settings of the attestation service env
+type smsData struct {
+	Providers   string       // comma separated, in order of preference
+	Countries   []smsCountry // providers of a country, overriding Providers
+	Twilio      bool
+	Nexmo       bool
+	MessageBird bool
+}
+
+type smsCountry struct {
+	Code      string
+	Providers string
+}
+
+func smsProviderNames() []string {
+	var names []string
+	for name := range smsProviderCredentials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// parseSMSCountry splits an entry of `sms_country_providers`: <country code>=<provider>;<provider>
+func parseSMSCountry(entry string) (smsCountry, []string, error) {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 || !countryCodeRegexp.MatchString(strings.TrimSpace(parts[0])) {
+		return smsCountry{}, nil, fmt.Errorf("%q is not <country code>=<provider>;<provider>, eg MX=messagebird;twilio", entry)
+	}
+
+	var providers []string
+	for _, provider := range strings.Split(parts[1], ";") {
+		if provider = strings.TrimSpace(provider); provider != "" {
+			providers = append(providers, provider)
+		}
+	}
+
+	return smsCountry{Code: strings.TrimSpace(parts[0]), Providers: strings.Join(providers, ",")}, providers, nil
+}
+
+// validateSMSProviderList checks that a list of providers is not empty, known and without duplicates
+func validateSMSProviderList(providers []string) error {
+	if len(providers) == 0 {
+		return fmt.Errorf("no SMS provider, must be any of: %s", strings.Join(smsProviderNames(), ", "))
+	}
+
+	seen := map[string]bool{}
+	for _, provider := range providers {
+		if _, ok := smsProviderCredentials[provider]; !ok {
+			return fmt.Errorf("unknown SMS provider %q, must be any of: %s", provider, strings.Join(smsProviderNames(), ", "))
+		}
+		if seen[provider] {
+			return fmt.Errorf("SMS provider %q is listed twice", provider)
+		}
+		seen[provider] = true
+	}
+
+	return nil
+}
+
+func validateSMSProviders(value string) error {
+	return validateSMSProviderList(splitList(value))
+}
+
+func validateSMSCountries(value string) error {
+	seen := map[string]bool{}
+	for _, entry := range splitList(value) {
+		country, providers, err := parseSMSCountry(entry)
+		if err != nil {
+			return err
+		}
+		if err := validateSMSProviderList(providers); err != nil {
+			return fmt.Errorf("%s: %s", country.Code, err)
+		}
+		if seen[country.Code] {
+			return fmt.Errorf("country %s is listed twice", country.Code)
+		}
+		seen[country.Code] = true
+	}
+
+	return nil
+}
+
+// smsProviders returns every provider of `sms_providers` and `sms_country_providers`
+func (config attestationServiceConfig) smsProviders() map[string]bool {
+	used := map[string]bool{}
+	for _, provider := range config.SMSProviders {
+		used[provider] = true
+	}
+	for _, entry := range config.SMSCountries {
+		if _, providers, err := parseSMSCountry(entry); err == nil {
+			for _, provider := range providers {
+				used[provider] = true
+			}
+		}
+	}
+
+	return used
+}
+
+// validateSMSCredentials reports the missing credentials of the providers in use
+func (c *Celo) validateSMSCredentials(config attestationServiceConfig) []string {
+	var problems []string
+
+	used := config.smsProviders()
+	for _, provider := range smsProviderNames() {
+		if !used[provider] {
+			continue
+		}
+		for _, name := range smsProviderCredentials[provider] {
+			if c.n.StrParameters[name] == "" {
+				problems = append(problems, fmt.Sprintf("%s: is mandatory with SMS provider %s", name, provider))
+			}
+		}
+	}
+
+	return problems
+}
+
+func (config attestationServiceConfig) smsData() smsData {
+	used := config.smsProviders()
+
+	data := smsData{
+		Providers:   strings.Join(config.SMSProviders, ","),
+		Twilio:      used["twilio"],
+		Nexmo:       used["nexmo"],
+		MessageBird: used["messagebird"],
+	}
+	// invalid entries are reported by Validate before the env is written
+	for _, entry := range config.SMSCountries {
+		if country, _, err := parseSMSCountry(entry); err == nil {
+			data.Countries = append(data.Countries, country)
+		}
+	}
+
+	return data
+}
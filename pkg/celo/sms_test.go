@@ -0,0 +1,83 @@
+package celo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSMSCountry(t *testing.T) {
+	tests := []struct {
+		entry         string
+		want          smsCountry
+		wantProviders []string
+		wantErr       bool
+	}{
+		{"MX=messagebird", smsCountry{"MX", "messagebird"}, []string{"messagebird"}, false},
+		{"MX=messagebird;twilio", smsCountry{"MX", "messagebird,twilio"}, []string{"messagebird", "twilio"}, false},
+		{" MX = messagebird ; twilio ", smsCountry{"MX", "messagebird,twilio"}, []string{"messagebird", "twilio"}, false},
+		{"MX=messagebird;;twilio;", smsCountry{"MX", "messagebird,twilio"}, []string{"messagebird", "twilio"}, false},
+		{"MX=", smsCountry{"MX", ""}, nil, false},
+		{"MX", smsCountry{}, nil, true},
+		{"mx=twilio", smsCountry{}, nil, true},
+		{"MEX=twilio", smsCountry{}, nil, true},
+		{"=twilio", smsCountry{}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			got, providers, err := parseSMSCountry(tt.entry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSMSCountry(%q) error = %v, wantErr %v", tt.entry, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSMSCountry(%q) = %v, want %v", tt.entry, got, tt.want)
+			}
+			if !reflect.DeepEqual(providers, tt.wantProviders) {
+				t.Errorf("parseSMSCountry(%q) providers = %v, want %v", tt.entry, providers, tt.wantProviders)
+			}
+		})
+	}
+}
+
+func TestValidateSMSCountries(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"one country", "MX=messagebird;twilio", false},
+		{"several countries", "MX=messagebird, US=twilio;nexmo", false},
+		{"invalid entry", "MX=messagebird,mexico", true},
+		{"no provider", "MX=", true},
+		{"unknown provider", "MX=sendgrid", true},
+		{"provider listed twice", "MX=twilio;twilio", true},
+		{"country listed twice", "MX=twilio,MX=nexmo", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSMSCountries(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("validateSMSCountries(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSMSData(t *testing.T) {
+	config := attestationServiceConfig{
+		SMSProviders: []string{"twilio"},
+		SMSCountries: []string{"MX=messagebird;twilio", "invalid", "US=nexmo"},
+	}
+
+	want := smsData{
+		Providers:   "twilio",
+		Countries:   []smsCountry{{"MX", "messagebird,twilio"}, {"US", "nexmo"}},
+		Twilio:      true,
+		Nexmo:       true,
+		MessageBird: true,
+	}
+	if got := config.smsData(); !reflect.DeepEqual(got, want) {
+		t.Errorf("smsData() = %+v, want %+v", got, want)
+	}
+}
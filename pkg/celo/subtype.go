@@ -0,0 +1,349 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// Subtype a kind of node, registered in subtypes by the value of the `subtype` parameter
+type Subtype interface {
+	// Config returns the typed parameters of the subtype, decodeConfig fills them
+	Config() subtypeConfig
+	// Parameters returns the parameters of the subtype
+	Parameters(c *Celo) []plugin.Parameter
+	// Containers returns the containers of the subtype
+	Containers(c *Celo) []docker.Container
+	// Templates returns the files create-configurations writes
+	Templates(c *Celo) (map[string]string, error)
+	// DockerCmdTpl returns the template of `celo.dockercmd`
+	DockerCmdTpl() string
+	// GethConfig returns the settings of `config.toml`, nil if the subtype does not run geth with it
+	GethConfig(c *Celo) *GethConfig
+	// Validate returns the problems of the parameters that are specific to the subtype
+	Validate(c *Celo) []string
+	// PreConfigure runs on create-configurations before the templates are rendered
+	PreConfigure(c *Celo) error
+	// PreStart runs on start before the containers are started
+	PreStart(c *Celo) error
+	// PostStart runs on start once the containers are running
+	PostStart(c *Celo) error
+	// Tests returns what the tester checks on the running node
+	Tests(c *Celo) Tests
+}
+
+// Tests what the tester checks on a running node
+type Tests struct {
+	Container       string // the container to test
+	RPC             bool   // wether the container serves json rpc on 8545
+	AttestationPort string // the container port of the attestation service, empty for nodes
+}
+
+// subtypes the kinds of nodes by the value of the `subtype` parameter, each call returns one with an empty config
+var subtypes = map[string]func() Subtype{
+	"proxy":               func() Subtype { return &proxySubtype{} },
+	"validator":           func() Subtype { return &validatorSubtype{} },
+	"fullnode":            func() Subtype { return &fullnodeSubtype{} },
+	"archive":             func() Subtype { return &archiveSubtype{} },
+	"light":               func() Subtype { return &lightSubtype{} },
+	"bootnode":            func() Subtype { return &bootnodeSubtype{} },
+	"devnet":              func() Subtype { return &devnetSubtype{} },
+	"attestation-node":    func() Subtype { return &attestationNodeSubtype{} },
+	"attestation-service": func() Subtype { return &attestationServiceSubtype{} },
+}
+
+// lookupSubtype returns the registered subtype, meta for any other value.
+// Only the meta command runs without a subtype, the others are rejected by CheckSubtype.
+func lookupSubtype(name string) Subtype {
+	if kind, ok := subtypes[name]; ok {
+		return kind()
+	}
+	return &metaSubtype{}
+}
+
+// subtypeNames returns the names of the registered subtypes, sorted
+func subtypeNames() []string {
+	var names []string
+	for name := range subtypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CheckSubtype fails if `subtype` is not a registered subtype, instead of running the
+// meta container that only prints the help of geth
+func (c *Celo) CheckSubtype() error {
+	if _, ok := subtypes[c.Subtype]; !ok {
+		return fmt.Errorf("unknown subtype %q, must be one of: %s", c.Subtype, strings.Join(subtypeNames(), ", "))
+	}
+	return nil
+}
+
+// baseSubtype the defaults of a subtype: no geth config, no own problems, no hooks and
+// a test of the container named after the subtype
+type baseSubtype struct{}
+
+func (baseSubtype) GethConfig(c *Celo) *GethConfig {
+	return nil
+}
+
+func (baseSubtype) Validate(c *Celo) []string {
+	return nil
+}
+
+func (baseSubtype) PreConfigure(c *Celo) error {
+	return nil
+}
+
+func (baseSubtype) PreStart(c *Celo) error {
+	return nil
+}
+
+func (baseSubtype) PostStart(c *Celo) error {
+	return nil
+}
+
+func (baseSubtype) Tests(c *Celo) Tests {
+	return Tests{Container: "bpm-" + c.n.ID + "-" + c.Subtype, RPC: true}
+}
+
+// gethSubtype the defaults of a subtype running geth on the chain of the network
+type gethSubtype struct {
+	baseSubtype
+}
+
+// Templates renders `celo.dockercmd`, the collector env and `config.toml`
+func (gethSubtype) Templates(c *Celo) (map[string]string, error) {
+	return c.gethTemplates()
+}
+
+// PreStart initializes the chain with the genesis
+func (gethSubtype) PreStart(c *Celo) error {
+	log.Println("Initialize genesis...")
+	if _, err := c.InitGenesis(); err != nil {
+		return fmt.Errorf("unable to initialize genesis: %s", err)
+	}
+	return nil
+}
+
+// metaSubtype lists all parameters so they appear in the bpm manifest, its config only has the common ones
+type metaSubtype struct {
+	baseSubtype
+	config commonConfig
+}
+
+func (s *metaSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*metaSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	return []plugin.Parameter{
+		p.subtype,
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.networkID,
+		p.genesisFile,
+		p.signer,
+		p.keystore,
+		p.keypass,
+		p.port,
+		p.proxyInternal,
+		p.proxyExternal,
+		p.enode,
+		p.proxies,
+		p.replica,
+		p.bootnodes,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.noUSB,
+		p.lightServe,
+		p.lightMaxpeers,
+		p.maxpeers,
+		p.cache,
+		p.syncMode,
+		p.lightServers,
+		p.externalIP,
+		p.account,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+		p.validators,
+		p.validator,
+		p.database,
+		p.dbSSLMode,
+		p.dbCAFile,
+		p.dbPassword,
+		p.dbUser,
+		p.postgresImage,
+		p.postgresVersion,
+		p.attNode,
+		p.smsProviders,
+		p.smsCountries,
+		p.twilioServiceSID,
+		p.twilioAccountSID,
+		p.twilioAuthToken,
+		p.twilioBlacklist,
+		p.nexmoKey,
+		p.nexmoSecret,
+		p.nexmoBlacklist,
+		p.messageBirdKey,
+	}
+}
+
+// Containers describes the proxy container, without parameters to publish its ports on
+func (*metaSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		(&proxySubtype{}).container(c),
+	}
+}
+
+func (*metaSubtype) Templates(c *Celo) (map[string]string, error) {
+	templates, err := c.dockerCmdTemplates()
+	if err != nil {
+		return nil, err
+	}
+	templates["configs/collector.env"] = c.mustRenderTemplate("configs/collector.env", configs.CollectorEnvTpl)
+	return templates, nil
+}
+
+func (*metaSubtype) DockerCmdTpl() string {
+	return "--help" // docker command required by sdk?
+}
+
+// dockerCmdTemplates renders `celo.dockercmd`
+func (c *Celo) dockerCmdTemplates() (map[string]string, error) {
+	dockerCmd, err := c.getDockerCmd()
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		c.cmdFile: dockerCmd,
+	}, nil
+}
+
+// gethTemplates renders `celo.dockercmd`, the collector env and `config.toml` of a subtype running geth
+func (c *Celo) gethTemplates() (map[string]string, error) {
+	templates, err := c.dockerCmdTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	templates["configs/collector.env"] = c.mustRenderTemplate("configs/collector.env", configs.CollectorEnvTpl)
+
+	// an invalid `config_toml` is reported by Validate before the templates are written
+	if config, err := c.getGethConfigToml(); err == nil {
+		templates["configs/config.toml"] = config
+	}
+
+	return templates, nil
+}
+
+// addKeystoreTemplates adds the keystore and its password of `signer`
+func (c *Celo) addKeystoreTemplates(templates map[string]string, config keystoreConfig) {
+	ks := c.getKeystore(config)
+	templates["configs/keystore/"+ks.filename] = ks.json // string
+	templates["configs/.password.secret"] = ks.pass
+}
+
+func (c *Celo) dataDirMount() docker.Mount {
+	return docker.Mount{
+		Type: "bind",
+		From: c.config.DataDir,
+		To:   "/root/.celo",
+	}
+}
+
+func configsMount() docker.Mount {
+	return docker.Mount{
+		Type: "bind",
+		From: "./configs",
+		To:   "/root/.celo/configs",
+	}
+}
+
+func configTomlMount() docker.Mount {
+	return docker.Mount{
+		Type: "bind",
+		From: "./configs/config.toml",
+		To:   "/root/config.toml",
+	}
+}
+
+// p2pPorts publishes the p2p port of geth, geth listens on `port` inside the container as well so
+// that the enode it advertises has the port peers reach it on
+func p2pPorts(config chainConfig) []docker.Port {
+	return []docker.Port{
+		{
+			HostIP:        "0.0.0.0",
+			HostPort:      config.Port,
+			ContainerPort: config.Port,
+			Protocol:      "tcp",
+		},
+		{
+			HostIP:        "0.0.0.0",
+			HostPort:      config.Port,
+			ContainerPort: config.Port,
+			Protocol:      "udp",
+		},
+	}
+}
+
+// rpcPorts publishes json rpc on `rpcaddr:rpcport` and, if enabled, websockets
+func rpcPorts(config rpcConfig) []docker.Port {
+	return append([]docker.Port{
+		{
+			HostIP:        config.RPCAddr,
+			HostPort:      config.RPCPort,
+			ContainerPort: "8545",
+		},
+	}, wsPorts(config)...)
+}
+
+// wsPorts publishes websockets on `wsaddr:wsport` if enabled
+func wsPorts(config rpcConfig) []docker.Port {
+	if !config.WS {
+		return nil
+	}
+	return []docker.Port{
+		{
+			HostIP:        config.WSAddr,
+			HostPort:      config.WSPort,
+			ContainerPort: "8546",
+			Protocol:      "tcp",
+		},
+	}
+}
+
+// collectorContainer reports the node state from the logs of the node
+func collectorContainer() docker.Container {
+	return docker.Container{
+		Name:        "collector",
+		Image:       "docker.io/blockdaemon/celo-collector:0.0.5",
+		EnvFilename: "configs/collector.env",
+		Mounts: []docker.Mount{
+			{
+				Type: "bind",
+				From: "logs",
+				To:   "/data/nodestate",
+			},
+		},
+		CollectLogs: true,
+	}
+}
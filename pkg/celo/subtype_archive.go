@@ -0,0 +1,90 @@
+package celo
+
+import (
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// archiveSubtype a full node keeping the state of every block
+type archiveSubtype struct {
+	gethSubtype
+	config archiveConfig
+}
+
+type archiveConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	rpcConfig
+	Bootnodes []string `param:"bootnodes"`
+	MaxPeers  int      `param:"maxpeers"`
+	Cache     int      `param:"cache"`
+	NoUSB     bool     `param:"nousb"`
+}
+
+func (s *archiveSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*archiveSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.bootnodes.Mandatory = true
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.genesisFile,
+		p.bootnodes,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.maxpeers,
+		p.cache,
+		p.port,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+		p.noUSB,
+	}
+}
+
+func (s *archiveSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "archive",
+			Image:   c.image,
+			CmdFile: c.cmdFile,
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+				configTomlMount(),
+			},
+			Ports:       append(p2pPorts(s.config.chainConfig), rpcPorts(s.config.rpcConfig)...),
+			CollectLogs: true,
+		},
+		collectorContainer(),
+	}
+}
+
+func (*archiveSubtype) DockerCmdTpl() string {
+	return configs.ArchiveCmdTpl
+}
+
+func (s *archiveSubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.rpc(s.config.rpcConfig)
+	g.set("Eth", "NoPruning", true, "gcmode")
+	g.set("Eth", "DatabaseCache", s.config.Cache, "cache")
+	g.bootnodes(s.config.Bootnodes)
+	g.peers(s.config.MaxPeers)
+	g.nousb(s.config.NoUSB)
+	return g
+}
@@ -0,0 +1,273 @@
+package celo
+
+import (
+	"fmt"
+	"strings"
+
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// attestationNodeSubtype the node the attestation service signs attestations with
+type attestationNodeSubtype struct {
+	gethSubtype
+	config attestationNodeConfig
+}
+
+type attestationNodeConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	rpcConfig
+	keystoreConfig
+	Bootnodes []string `param:"bootnodes"`
+}
+
+func (s *attestationNodeSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*attestationNodeSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.signer.Mandatory = true
+	p.keystore.Mandatory = true
+	p.keypass.Mandatory = true
+	p.bootnodes.Mandatory = true
+	p.rpcaddr.Default = "127.0.0.1" // the attestation service reaches the node on the docker network, the signer is unlocked
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.networkID,
+		p.genesisFile,
+		p.signer,
+		p.keystore,
+		p.keypass,
+		p.bootnodes,
+		p.port,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+	}
+}
+
+func (s *attestationNodeSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "attestation-node",
+			Image:   c.image,
+			CmdFile: c.cmdFile,
+			Ports:   append(p2pPorts(s.config.chainConfig), rpcPorts(s.config.rpcConfig)...),
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+				configTomlMount(),
+				configsMount(),
+			},
+			CollectLogs: true,
+		},
+	}
+}
+
+// Templates adds the keystore of `signer` to the geth templates
+func (s *attestationNodeSubtype) Templates(c *Celo) (map[string]string, error) {
+	templates, err := c.gethTemplates()
+	if err != nil {
+		return nil, err
+	}
+	c.addKeystoreTemplates(templates, s.config.keystoreConfig)
+	return templates, nil
+}
+
+func (*attestationNodeSubtype) DockerCmdTpl() string {
+	return configs.AttestationCmdTpl
+}
+
+func (s *attestationNodeSubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.rpc(s.config.rpcConfig)
+	g.keystore()
+	g.bootnodes(s.config.Bootnodes)
+	return g
+}
+
+// attestationServicePort the port the attestation service listens on inside its container, `port` publishes it
+const attestationServicePort = "3000"
+
+// attestationServiceSubtype the attestation service with its postgres database, or with the external one of `database`
+type attestationServiceSubtype struct {
+	baseSubtype
+	config attestationServiceConfig
+}
+
+type attestationServiceConfig struct {
+	commonConfig
+	Signer           string   `param:"signer"`
+	Validator        string   `param:"validator"`
+	Port             string   `param:"port"`
+	Database         string   `param:"database"`
+	DBSSLMode        string   `param:"db_sslmode"`
+	DBCAFile         string   `param:"db_ca_file"`
+	DBUser           string   `param:"db_user"`
+	DBPassword       string   `param:"db_password"`
+	PostgresImage    string   `param:"postgres_image"`
+	PostgresVersion  string   `param:"postgres_version"`
+	NodeURL          string   `param:"node_url"`
+	SMSProviders     []string `param:"sms_providers"`
+	SMSCountries     []string `param:"sms_country_providers"`
+	TwilioServiceSID string   `param:"twilio_service_sid"`
+	TwilioAccountSID string   `param:"twilio_account_sid"`
+	TwilioAuthToken  string   `param:"twilio_auth_token"`
+	TwilioBlacklist  string   `param:"twilio_blacklist"`
+	NexmoKey         string   `param:"nexmo_key"`
+	NexmoSecret      string   `param:"nexmo_secret"`
+	NexmoBlacklist   string   `param:"nexmo_blacklist"`
+	MessageBirdKey   string   `param:"messagebird_api_key"`
+}
+
+func (s *attestationServiceSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*attestationServiceSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.port.Description = "Host port the attestation service is published on"
+	p.port.Default = "80"
+	p.signer.Mandatory = true
+	p.validator.Mandatory = true
+	p.attNode.Mandatory = true
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.signer,
+		p.validator,
+		p.database,
+		p.dbSSLMode,
+		p.dbCAFile,
+		p.dbUser,
+		p.dbPassword,
+		p.postgresImage,
+		p.postgresVersion,
+		p.attNode,
+		p.smsProviders,
+		p.smsCountries,
+		p.twilioServiceSID,
+		p.twilioAccountSID,
+		p.twilioAuthToken,
+		p.port,
+		p.twilioBlacklist,
+		p.nexmoKey,
+		p.nexmoSecret,
+		p.nexmoBlacklist,
+		p.messageBirdKey,
+		// p.celoCommands,
+	}
+}
+
+func (s *attestationServiceSubtype) Containers(c *Celo) []docker.Container {
+	var containers []docker.Container
+
+	if s.config.Database == "" {
+		containers = append(containers, docker.Container{
+			Name:        "attestation-postgres",
+			Image:       postgresImage(s.config),
+			EnvFilename: "configs/postgres.env",
+			Mounts: []docker.Mount{
+				c.postgresMount(),
+			},
+			CollectLogs: false,
+		})
+	}
+
+	var mounts []docker.Mount
+	if s.config.DBCAFile != "" {
+		mounts = append(mounts, docker.Mount{
+			Type: "bind",
+			From: s.config.DBCAFile,
+			To:   dbCAFile,
+		})
+	}
+
+	return append(containers, docker.Container{
+		Name:    "attestation-service",
+		Image:   c.imageAttestation,
+		CmdFile: c.cmdFile,
+		Ports: []docker.Port{
+			{
+				HostIP:        "0.0.0.0",
+				HostPort:      s.config.Port,
+				ContainerPort: attestationServicePort,
+				Protocol:      "tcp",
+			},
+		},
+		Mounts:      mounts,
+		CollectLogs: false,
+		EnvFilename: "configs/attestation-service.env",
+	})
+}
+
+func (s *attestationServiceSubtype) Templates(c *Celo) (map[string]string, error) {
+	templates, err := c.dockerCmdTemplates()
+	if err != nil {
+		return nil, err
+	}
+	templates["configs/attestation-service.env"] = c.mustRenderTemplate("configs/attestation-service.env", configs.AttesetationServiceEnvs)
+	if s.config.Database == "" {
+		templates["configs/postgres.env"] = c.mustRenderTemplate("configs/postgres.env", configs.PostgresEnvs)
+	}
+	return templates, nil
+}
+
+func (*attestationServiceSubtype) DockerCmdTpl() string {
+	return configs.AttestationServiceCmdTpl
+}
+
+// Tests checks the status and health of the service instead of geth
+func (*attestationServiceSubtype) Tests(c *Celo) Tests {
+	return Tests{Container: "bpm-" + c.n.ID + "-attestation-service", AttestationPort: attestationServicePort}
+}
+
+// Validate rejects line breaks and requires the credentials of the SMS providers in use and of the
+// postgres container, an external database may have them in its url
+func (s *attestationServiceSubtype) Validate(c *Celo) []string {
+	problems := c.validateSMSCredentials(s.config)
+
+	// the parameters end up in env files, which have no way to escape a line break
+	for _, p := range c.GetParameters() {
+		if strings.ContainsAny(c.n.StrParameters[p.Name], "\n\r") {
+			problems = append(problems, fmt.Sprintf("%s: can not contain a line break", p.Name))
+		}
+	}
+
+	if s.config.Database != "" {
+		return problems
+	}
+
+	if s.config.DBUser == "" {
+		problems = append(problems, "db_user: is mandatory without database")
+	}
+	if s.config.DBPassword == "" {
+		problems = append(problems, "db_password: is mandatory without database")
+	}
+	return problems
+}
+
+// PreStart keeps the database from being opened by an incompatible postgres
+func (s *attestationServiceSubtype) PreStart(c *Celo) error {
+	if s.config.Database != "" {
+		return nil
+	}
+	if err := c.checkPostgresDir(s.config); err != nil {
+		return fmt.Errorf("unable to use postgres data: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// bootnodeSubtype a discovery only bootnode other nodes use as `bootnodes`
+type bootnodeSubtype struct {
+	baseSubtype
+	config bootnodeConfig
+}
+
+type bootnodeConfig struct {
+	commonConfig
+	logConfig
+	Port       string `param:"port"`
+	ExternalIP string `param:"external_ip"`
+}
+
+func (s *bootnodeSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*bootnodeSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.port.Default = "30301"
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.port,
+		p.externalIP,
+	}
+}
+
+func (s *bootnodeSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "bootnode",
+			Image:   c.imageTools,
+			CmdFile: c.cmdFile,
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+			},
+			Ports: []docker.Port{
+				{
+					HostIP:        "0.0.0.0",
+					HostPort:      s.config.Port,
+					ContainerPort: "30301",
+					Protocol:      "udp",
+				},
+			},
+			CollectLogs: true,
+		},
+	}
+}
+
+func (*bootnodeSubtype) Templates(c *Celo) (map[string]string, error) {
+	return c.dockerCmdTemplates()
+}
+
+func (*bootnodeSubtype) DockerCmdTpl() string {
+	return configs.BootnodeCmdTpl
+}
+
+// PreConfigure creates the node key so the enode url is known before the first start
+func (s *bootnodeSubtype) PreConfigure(c *Celo) error {
+	return s.createKey(c)
+}
+
+func (s *bootnodeSubtype) PreStart(c *Celo) error {
+	return s.createKey(c)
+}
+
+// Tests skips json rpc, a bootnode does not serve it
+func (*bootnodeSubtype) Tests(c *Celo) Tests {
+	return Tests{Container: "bpm-" + c.n.ID + "-bootnode"}
+}
+
+// createKey creates the node key if missing and logs the enode url
+func (s *bootnodeSubtype) createKey(c *Celo) error {
+	if err := c.CreateBootnodeKey(); err != nil {
+		return fmt.Errorf("unable to create bootnode key: %s", err)
+	}
+
+	enode, err := c.BootnodeEnode(s.config)
+	if err != nil {
+		return fmt.Errorf("unable to get bootnode enode: %s", err)
+	}
+	log.Printf("Bootnode enode url: %s\n", enode)
+
+	return nil
+}
@@ -0,0 +1,100 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// devnetSubtype a private chain of `validators` mining validators
+type devnetSubtype struct {
+	baseSubtype
+	config devnetConfig
+}
+
+type devnetConfig struct {
+	commonConfig
+	logConfig
+	NetworkID  string `param:"networkid"`
+	Validators int    `param:"validators"`
+	RPCAddr    string `param:"rpcaddr"`
+	RPCPort    string `param:"rpcport"`
+}
+
+func (s *devnetSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*devnetSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.validators,
+		p.rpcaddr,
+		p.rpcPort,
+	}
+}
+
+func (s *devnetSubtype) Containers(c *Celo) []docker.Container {
+	return c.getDevnetContainers(s.config)
+}
+
+// Templates renders the command of every validator, they only exist once `CreateDevnet` ran
+func (*devnetSubtype) Templates(c *Celo) (map[string]string, error) {
+	templates := map[string]string{}
+
+	dn, err := c.loadDevnet()
+	if err != nil {
+		return templates, nil
+	}
+	for i, v := range dn.Validators {
+		cmd, err := c.getDevnetCmd(i, v)
+		if err != nil {
+			return nil, fmt.Errorf("devnet validator %d: %s", i, err)
+		}
+		templates[fmt.Sprintf("devnet-validator-%d.dockercmd", i)] = cmd
+	}
+
+	return templates, nil
+}
+
+// DockerCmdTpl is empty, every validator has its own command
+func (*devnetSubtype) DockerCmdTpl() string {
+	return ""
+}
+
+// PreConfigure generates the keys and the genesis, they have to exist before the templates are rendered
+func (s *devnetSubtype) PreConfigure(c *Celo) error {
+	if err := c.CreateDevnet(s.config); err != nil {
+		return fmt.Errorf("unable to create devnet: %s", err)
+	}
+	return nil
+}
+
+func (*devnetSubtype) PreStart(c *Celo) error {
+	log.Println("Initialize genesis...")
+	if _, err := c.InitDevnetGenesis(); err != nil {
+		return fmt.Errorf("unable to initialize genesis: %s", err)
+	}
+	return nil
+}
+
+// PostStart connects the validators, container ips are only known once they are running
+func (*devnetSubtype) PostStart(c *Celo) error {
+	log.Println("Connecting devnet validators...")
+	if err := c.ConnectDevnet(); err != nil {
+		return fmt.Errorf("unable to connect devnet validators: %s", err)
+	}
+	return nil
+}
+
+func (*devnetSubtype) Tests(c *Celo) Tests {
+	return Tests{Container: c.devnetContainerName(0), RPC: true}
+}
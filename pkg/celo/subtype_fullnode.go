@@ -0,0 +1,95 @@
+package celo
+
+import (
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// fullnodeSubtype a full node that also serves light clients
+type fullnodeSubtype struct {
+	gethSubtype
+	config fullnodeConfig
+}
+
+type fullnodeConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	rpcConfig
+	Bootnodes  []string `param:"bootnodes"`
+	MaxPeers   int      `param:"maxpeers"`
+	LightServe int      `param:"light_serve"`
+	LightPeers int      `param:"light_maxpeers"`
+	Account    string   `param:"account"`
+	NoUSB      bool     `param:"nousb"`
+}
+
+func (s *fullnodeSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*fullnodeSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.bootnodes.Mandatory = true
+	p.account.Mandatory = true
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.genesisFile,
+		p.bootnodes,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.lightServe,
+		p.lightMaxpeers,
+		p.maxpeers,
+		p.account,
+		p.port,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+		p.noUSB,
+	}
+}
+
+func (s *fullnodeSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "fullnode",
+			Image:   c.image,
+			CmdFile: c.cmdFile,
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+				configTomlMount(),
+			},
+			Ports:       append(p2pPorts(s.config.chainConfig), rpcPorts(s.config.rpcConfig)...),
+			CollectLogs: true,
+		},
+		collectorContainer(),
+	}
+}
+
+func (*fullnodeSubtype) DockerCmdTpl() string {
+	return configs.FullnodeCmdTpl
+}
+
+func (s *fullnodeSubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.rpc(s.config.rpcConfig)
+	g.set("Eth", "LightServ", s.config.LightServe, "light.serve")
+	g.set("Eth", "LightPeers", s.config.LightPeers, "light.maxpeers")
+	g.bootnodes(s.config.Bootnodes)
+	g.peers(s.config.MaxPeers)
+	g.nousb(s.config.NoUSB)
+	return g
+}
@@ -0,0 +1,92 @@
+package celo
+
+import (
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// lightSubtype a light client, optionally pinned to `light_servers`
+type lightSubtype struct {
+	gethSubtype
+	config lightConfig
+}
+
+type lightConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	rpcConfig
+	Bootnodes    []string `param:"bootnodes"`
+	MaxPeers     int      `param:"maxpeers"`
+	SyncMode     string   `param:"syncmode"`
+	LightServers []string `param:"light_servers"`
+	NoUSB        bool     `param:"nousb"`
+}
+
+func (s *lightSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*lightSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.bootnodes.Mandatory = true
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.genesisFile,
+		p.bootnodes,
+		p.syncMode,
+		p.lightServers,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.maxpeers,
+		p.port,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+		p.noUSB,
+	}
+}
+
+func (s *lightSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "light",
+			Image:   c.image,
+			CmdFile: c.cmdFile,
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+				configTomlMount(),
+			},
+			Ports:       append(p2pPorts(s.config.chainConfig), rpcPorts(s.config.rpcConfig)...),
+			CollectLogs: true,
+		},
+		collectorContainer(),
+	}
+}
+
+func (*lightSubtype) DockerCmdTpl() string {
+	return configs.LightCmdTpl
+}
+
+func (s *lightSubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.rpc(s.config.rpcConfig)
+	g.set("Eth", "SyncMode", s.config.SyncMode, "syncmode")
+	g.set("Node.P2P", "StaticNodes", s.config.LightServers)
+	g.bootnodes(s.config.Bootnodes)
+	g.peers(s.config.MaxPeers)
+	g.nousb(s.config.NoUSB)
+	return g
+}
@@ -0,0 +1,95 @@
+package celo
+
+import (
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// proxySubtype a proxy between a validator and the network
+type proxySubtype struct {
+	gethSubtype
+	config proxyConfig
+}
+
+type proxyConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	rpcConfig
+	Signer    string   `param:"signer"`
+	Bootnodes []string `param:"bootnodes"`
+}
+
+func (s *proxySubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*proxySubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.signer.Mandatory = true
+	p.bootnodes.Mandatory = true
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.genesisFile,
+		p.rpcaddr,
+		p.rpcPort,
+		p.rpcAPI,
+		p.rpcCorsDomain,
+		p.ws,
+		p.wsAddr,
+		p.wsPort,
+		p.wsAPI,
+		p.port,
+		p.signer,
+		p.bootnodes,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+	}
+}
+
+func (s *proxySubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		s.container(c),
+		collectorContainer(),
+	}
+}
+
+// container the proxy, validators connect to its internal endpoint on 30503
+func (s *proxySubtype) container(c *Celo) docker.Container {
+	ports := append(p2pPorts(s.config.chainConfig), docker.Port{
+		HostIP:        "0.0.0.0",
+		HostPort:      "30503",
+		ContainerPort: "30503",
+		Protocol:      "tcp",
+	})
+
+	return docker.Container{
+		Name:    "proxy",
+		Image:   c.image,
+		CmdFile: c.cmdFile,
+		Mounts: []docker.Mount{
+			c.dataDirMount(),
+			configTomlMount(),
+		},
+		Ports:       append(ports, rpcPorts(s.config.rpcConfig)...),
+		CollectLogs: true,
+	}
+}
+
+func (*proxySubtype) DockerCmdTpl() string {
+	return configs.ProxyCmdTpl
+}
+
+func (s *proxySubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.rpc(s.config.rpcConfig)
+	g.bootnodes(s.config.Bootnodes)
+	return g
+}
@@ -0,0 +1,108 @@
+package celo
+
+import (
+	"go.blockdaemon.com/bpm/celo/configs"
+	"go.blockdaemon.com/bpm/sdk/pkg/docker"
+	"go.blockdaemon.com/bpm/sdk/pkg/plugin"
+)
+
+// validatorSubtype a validator, only connected to the network through its proxies
+type validatorSubtype struct {
+	gethSubtype
+	config validatorConfig
+}
+
+type validatorConfig struct {
+	commonConfig
+	logConfig
+	chainConfig
+	keystoreConfig
+	Enode         string   `param:"enode"`
+	ProxyInternal string   `param:"proxy_internal"`
+	ProxyExternal string   `param:"proxy_external"`
+	Proxies       []string `param:"proxies"`
+	Replica       bool     `param:"replica"`
+}
+
+func (s *validatorSubtype) Config() subtypeConfig {
+	return &s.config
+}
+
+func (*validatorSubtype) Parameters(c *Celo) []plugin.Parameter {
+	p := c.parameterSet()
+	p.signer.Mandatory = true
+	p.keystore.Mandatory = true
+	p.keypass.Mandatory = true
+	// at least one proxy is required, either single or in `proxies`
+	return []plugin.Parameter{
+		p.network,
+		p.networkFile,
+		p.verbosity,
+		p.vmodule,
+		p.subtype,
+		p.networkID,
+		p.genesisFile,
+		p.signer,
+		p.keystore,
+		p.keypass,
+		p.port,
+		p.proxyInternal,
+		p.proxyExternal,
+		p.enode,
+		p.proxies,
+		p.replica,
+		p.celoCommands,
+		p.celoOverride,
+		p.configToml,
+	}
+}
+
+func (s *validatorSubtype) Containers(c *Celo) []docker.Container {
+	return []docker.Container{
+		{
+			Name:    "validator",
+			Image:   c.image,
+			CmdFile: c.cmdFile,
+			Mounts: []docker.Mount{
+				c.dataDirMount(),
+				configTomlMount(),
+				configsMount(),
+			},
+			Ports:       p2pPorts(s.config.chainConfig),
+			CollectLogs: true,
+		},
+		collectorContainer(),
+	}
+}
+
+// Templates adds the keystore of `signer` to the geth templates
+func (s *validatorSubtype) Templates(c *Celo) (map[string]string, error) {
+	templates, err := c.gethTemplates()
+	if err != nil {
+		return nil, err
+	}
+	c.addKeystoreTemplates(templates, s.config.keystoreConfig)
+	return templates, nil
+}
+
+func (*validatorSubtype) DockerCmdTpl() string {
+	return configs.ValidatorCmdTpl
+}
+
+func (s *validatorSubtype) GethConfig(c *Celo) *GethConfig {
+	g := newGethConfig(c, s.config.chainConfig)
+	g.set("Node.P2P", "NoDiscovery", true, "nodiscover")
+	g.keystore()
+	return g
+}
+
+// Validate checks the proxies of the validator
+func (s *validatorSubtype) Validate(c *Celo) []string {
+	_, problems := s.config.validatorProxies()
+	return problems
+}
+
+// Tests skips json rpc, a validator does not serve it
+func (*validatorSubtype) Tests(c *Celo) Tests {
+	return Tests{Container: "bpm-" + c.n.ID + "-validator"}
+}
@@ -0,0 +1,98 @@
+package celo
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/node"
+)
+
+// SwapValidator schedules a hot swap from the primary validator to its replica at block,
+// or at the current block plus n when block is `+n`. Validators do not serve http rpc so
+// the istanbul api is called over ipc in the containers.
+func SwapValidator(primaryFile string, replicaFile string, block string) error {
+
+	primary, err := loadValidator(primaryFile)
+	if err != nil {
+		return err
+	}
+	replica, err := loadValidator(replicaFile)
+	if err != nil {
+		return err
+	}
+
+	if !strings.EqualFold(primary.StrParameters["signer"], replica.StrParameters["signer"]) {
+		return fmt.Errorf("primary signer %s and replica signer %s differ", primary.StrParameters["signer"], replica.StrParameters["signer"])
+	}
+	if !strings.EqualFold(replica.StrParameters["replica"], "true") {
+		return fmt.Errorf("%s is not configured with replica=true", replicaFile)
+	}
+
+	primaryContainer := "bpm-" + primary.ID + "-validator"
+	replicaContainer := "bpm-" + replica.ID + "-validator"
+
+	out, err := gethExec(primaryContainer, "eth.blockNumber")
+	if err != nil {
+		return fmt.Errorf("unable to get current block of %s: %s", primaryContainer, err)
+	}
+	current, err := strconv.ParseUint(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unable to parse current block %q: %s", out, err)
+	}
+
+	target, err := swapBlock(block, current)
+	if err != nil {
+		return err
+	}
+
+	// the replica starts first so that a failure leaves the primary validating
+	if _, err := gethExec(replicaContainer, fmt.Sprintf("istanbul.startValidatingAtBlock(%d)", target)); err != nil {
+		return fmt.Errorf("unable to schedule replica %s: %s", replicaContainer, err)
+	}
+	if _, err := gethExec(primaryContainer, fmt.Sprintf("istanbul.stopValidatingAtBlock(%d)", target)); err != nil {
+		return fmt.Errorf("replica %s is scheduled but primary %s is not, run `istanbul.stopValidatingAtBlock(%d)` on it or restart the replica: %s", replicaContainer, primaryContainer, target, err)
+	}
+
+	log.Printf("Scheduled swap from %s to %s at block %d (current block %d)\n", primary.ID, replica.ID, target, current)
+
+	return nil
+}
+
+// swapBlock returns the block to swap at, block or the current block plus n when block is `+n`
+func swapBlock(block string, current uint64) (uint64, error) {
+	var target uint64
+	if strings.HasPrefix(block, "+") {
+		n, err := strconv.ParseUint(strings.TrimPrefix(block, "+"), 10, 64)
+		if err != nil || n == 0 {
+			return 0, fmt.Errorf("%q is not a number of blocks greater than 0", block)
+		}
+		if n > math.MaxUint64-current {
+			return 0, fmt.Errorf("%q blocks after block %d is not a block number", block, current)
+		}
+		target = current + n
+	} else {
+		var err error
+		if target, err = strconv.ParseUint(block, 10, 64); err != nil {
+			return 0, fmt.Errorf("%q is not a block number", block)
+		}
+	}
+	if target <= current {
+		return 0, fmt.Errorf("block %d has already passed, current block is %d", target, current)
+	}
+
+	return target, nil
+}
+
+func loadValidator(file string) (node.Node, error) {
+	n, err := node.Load(file)
+	if err != nil {
+		return n, fmt.Errorf("unable to load node %s: %s", file, err)
+	}
+	if n.StrParameters["subtype"] != "validator" {
+		return n, fmt.Errorf("%s is not a validator but %q", file, n.StrParameters["subtype"])
+	}
+	return n, nil
+}
@@ -0,0 +1,43 @@
+package celo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSwapBlock(t *testing.T) {
+	tests := []struct {
+		name    string
+		block   string
+		current uint64
+		want    uint64
+		wantErr bool
+	}{
+		{"blocks ahead", "+100", 1000, 1100, false},
+		{"one block ahead", "+1", 1000, 1001, false},
+		{"zero blocks ahead", "+0", 1000, 0, true},
+		{"negative blocks ahead", "+-5", 1000, 0, true},
+		{"blocks ahead not a number", "+ten", 1000, 0, true},
+		{"plus only", "+", 1000, 0, true},
+		{"blocks ahead up to the last block", "+1", math.MaxUint64 - 1, math.MaxUint64, false},
+		{"blocks ahead past the last block", "+2", math.MaxUint64 - 1, 0, true},
+		{"block", "1100", 1000, 1100, false},
+		{"next block", "1001", 1000, 1001, false},
+		{"current block", "1000", 1000, 0, true},
+		{"passed block", "999", 1000, 0, true},
+		{"block not a number", "latest", 1000, 0, true},
+		{"negative block", "-5", 1000, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := swapBlock(tt.block, tt.current)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("swapBlock(%q, %d) error = %v, wantErr %v", tt.block, tt.current, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("swapBlock(%q, %d) = %d, want %d", tt.block, tt.current, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package celo
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"text/template"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/node"
+)
+
+// templateData the data the templates of this plugin are rendered with, before the sdk writes them.
+// Config is the config of the subtype, a template using a parameter of another subtype fails to render.
+type templateData struct {
+	Node               node.Node
+	Config             subtypeConfig
+	ProxyEnodeURLPairs string
+	DatabaseURL        string
+	SMS                smsData
+	AttestationPort    string
+	Validator          devnetValidator
+	ValidatorIndex     int
+}
+
+func (c *Celo) templateData() templateData {
+	data := templateData{
+		Node:   c.n,
+		Config: c.kind.Config(),
+	}
+
+	switch config := data.Config.(type) {
+	case *validatorConfig:
+		// invalid proxies are reported by Validate, the pairs of the valid ones are rendered anyway
+		proxies, _ := config.validatorProxies()
+		data.ProxyEnodeURLPairs = proxyEnodeURLPairs(proxies)
+	case *attestationServiceConfig:
+		data.DatabaseURL = c.getDBUrl(*config)
+		data.SMS = config.smsData()
+		data.AttestationPort = attestationServicePort
+	}
+
+	return data
+}
+
+// templateFuncs the functions available to the templates of this plugin
+var templateFuncs = template.FuncMap{
+	"env": envValue,
+}
+
+// renderTemplate renders a template with the typed parameters, a missing field fails instead of rendering empty
+func renderTemplate(name string, tpl string, data templateData) (string, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(tpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// mustRenderTemplate renders a template of the plugin itself, an error is a bug in the template
+func (c *Celo) mustRenderTemplate(name string, tpl string) string {
+	content, err := renderTemplate(name, tpl, c.templateData())
+	if err != nil {
+		log.Fatalf("Unable to render %s: %s\n", name, err)
+	}
+	return content
+}
+
+// envValue renders a value of an env file. Env files are read line by line and split at the first `=`,
+// without quoting or escapes, so `=` needs none but a line break would end the value and start another variable.
+func envValue(value string) (string, error) {
+	if strings.ContainsAny(value, "\n\r") {
+		return "", errors.New("a value of an env file can not contain a line break")
+	}
+	return value, nil
+}
+
+// escapeSDKTemplate keeps the sdk, which renders the templates it writes once more, from interpreting
+// `{{` in content the plugin rendered already, eg in a password
+func escapeSDKTemplate(content string) string {
+	return strings.Replace(content, "{{", `{{ "{{" }}`, -1)
+}
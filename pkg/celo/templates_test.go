@@ -0,0 +1,53 @@
+package celo
+
+import "testing"
+
+func TestEnvValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"empty", "", "", false},
+		{"plain", "AC123", "AC123", false},
+		{"equals", "a=b", "a=b", false},
+		{"quotes and spaces", `it's "quoted" `, `it's "quoted" `, false},
+		{"dollar and backslash", `pa$$\word`, `pa$$\word`, false},
+		{"url", "postgres://u:p%40ss@db:5432/attestation?sslmode=require", "postgres://u:p%40ss@db:5432/attestation?sslmode=require", false},
+		{"line feed", "a\nB=c", "", true},
+		{"carriage return", "a\rb", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := envValue(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("envValue(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("envValue(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeSDKTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"no template", "PASSWORD=secret\n", "PASSWORD=secret\n"},
+		{"braces", "PASSWORD=a{{b}}\n", `PASSWORD=a{{ "{{" }}b}}` + "\n"},
+		{"single brace", "PASSWORD={a}\n", "PASSWORD={a}\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeSDKTemplate(tt.content); got != tt.want {
+				t.Errorf("escapeSDKTemplate(%q) = %q, want %q", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,349 @@
+package celo
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/crypto/sha3"
+)
+
+// parameterValidators checks the content of a parameter, they only run for the parameters of the current subtype
+var parameterValidators = map[string]func(string) error{
+	"networkid":             validateNumber,
+	"network-file":          validateFile,
+	"genesis-file":          validateFile,
+	"config_toml":           validateTomlFile,
+	"signer":                validateAddress,
+	"validator":             validateAddress,
+	"account":               validateAddress,
+	"nousb":                 validateBool,
+	"celo_override":         validateBool,
+	"replica":               validateBool,
+	"verbosity":             validateVerbosity,
+	"vmodule":               validateVmodule,
+	"bootnodes":             validateEnodeURLs,
+	"keystore-file":         validateFile,
+	"keystore-pass":         validateFile,
+	"port":                  validatePort,
+	"proxy_internal":        validateIP,
+	"proxy_external":        validateProxyExternal,
+	"enode":                 validateNodeID,
+	"rpcaddr":               validateIP,
+	"rpcport":               validatePort,
+	"rpcapi":                validateAPIs,
+	"ws":                    validateBool,
+	"wsaddr":                validateIP,
+	"wsport":                validatePort,
+	"wsapi":                 validateAPIs,
+	"light_serve":           validateNumber,
+	"light_maxpeers":        validateNumber,
+	"maxpeers":              validateNumber,
+	"cache":                 validatePositiveNumber,
+	"syncmode":              validateLightSyncMode,
+	"light_servers":         validateEnodeURLs,
+	"external_ip":           validateIP,
+	"validators":            validatePositiveNumber,
+	"node_url":              validateURL,
+	"postgres_version":      validatePostgresVersion,
+	"database":              validateDatabaseURL,
+	"db_sslmode":            validateSSLMode,
+	"db_ca_file":            validateFile,
+	"sms_providers":         validateSMSProviders,
+	"sms_country_providers": validateSMSCountries,
+}
+
+// rpcAPIs the api namespaces celo geth serves over rpc and websocket
+var rpcAPIs = map[string]bool{
+	"admin":    true,
+	"debug":    true,
+	"eth":      true,
+	"istanbul": true,
+	"les":      true,
+	"miner":    true,
+	"net":      true,
+	"personal": true,
+	"txpool":   true,
+	"web3":     true,
+}
+
+var (
+	nodeIDRegexp  = regexp.MustCompile(`^[0-9a-fA-F]{128}$`)
+	addressRegexp = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+)
+
+// Validate checks the parameters of the current subtype and their consistency with the
+// other bpm nodes on this host before the configuration is written, reporting all problems at once
+func (c *Celo) Validate() error {
+	problems := c.parameterProblems()
+
+	// the docker command merges the `celo` flags, invalid ones are only reported once
+	celoValid := true
+	if chain := c.chain(); chain != nil && chain.Celo != "" {
+		if err := c.validateCeloFlags(*chain); err != nil {
+			problems = append(problems, fmt.Sprintf("celo: %s", err))
+			celoValid = false
+		}
+	}
+
+	if _, err := c.getDockerCmd(); celoValid && err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %s", c.cmdFile, err))
+	}
+
+	if err := c.validateNetworkID(); err != nil {
+		problems = append(problems, fmt.Sprintf("networkid: %s", err))
+	}
+
+	problems = append(problems, c.kind.Validate(c)...)
+
+	// only parse the genesis and look at the host when the parameters themselves are ok
+	if len(problems) == 0 {
+		if err := c.ValidateGenesisFile(); err != nil {
+			problems = append(problems, fmt.Sprintf("genesis-file: %s", err))
+		}
+		problems = append(problems, c.checkHost()...)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid parameters for %s:\n  - %s", c.Subtype, strings.Join(problems, "\n  - "))
+	}
+
+	return nil
+}
+
+// validateNetworkID rejects a `networkid` other than the one of a built-in network, the node would run
+// the genesis of the network on another network id. A genesis-file or network file brings its own chain.
+func (c *Celo) validateNetworkID() error {
+	chain := c.chain()
+	if chain == nil || !c.builtinNetwork || chain.GenesisFile != "" || chain.NetworkID == c.networkID {
+		return nil
+	}
+	return fmt.Errorf("%s is not the network id %s of network %s, set genesis-file or define the network in a network-file to run another chain", chain.NetworkID, c.networkID, c.config.Network)
+}
+
+// parameterProblems checks the parameters of the current subtype with their defaults applied,
+// the same values decodeConfig decodes
+func (c *Celo) parameterProblems() []string {
+	var problems []string
+
+	for _, p := range c.GetParameters() {
+		value := c.parameterValue(p)
+		if value == "" {
+			if p.Mandatory {
+				problems = append(problems, fmt.Sprintf("%s: is mandatory", p.Name))
+			}
+			continue
+		}
+
+		if validator, ok := parameterValidators[p.Name]; ok {
+			if err := validator(value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %s", p.Name, err))
+			}
+		}
+	}
+
+	return problems
+}
+
+func validateNumber(value string) error {
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	return nil
+}
+
+func validatePositiveNumber(value string) error {
+	if n, err := strconv.ParseUint(value, 10, 64); err != nil || n == 0 {
+		return fmt.Errorf("%q is not a number greater than 0", value)
+	}
+	return nil
+}
+
+func validateVerbosity(value string) error {
+	if level, err := strconv.Atoi(value); err != nil || level < 0 || level > 5 {
+		return fmt.Errorf("%q is not a log level between 0 and 5", value)
+	}
+	return nil
+}
+
+// validateVmodule checks a comma separated list of `<pattern>=<level>`
+func validateVmodule(value string) error {
+	for _, rule := range strings.Split(value, ",") {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return fmt.Errorf("%q is not <pattern>=<level>", rule)
+		}
+		if err := validateVerbosity(strings.TrimSpace(parts[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDatabaseURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") || u.Host == "" {
+		return fmt.Errorf("%q is not a postgres url, eg postgres://db.example.com:5432/attestation", value)
+	}
+	return nil
+}
+
+func validateSSLMode(value string) error {
+	switch value {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+		return nil
+	}
+	return fmt.Errorf("%q must be one of disable, allow, prefer, require, verify-ca or verify-full", value)
+}
+
+func validatePostgresVersion(value string) error {
+	if postgresMajorVersion(value) == "" {
+		return fmt.Errorf("%q is not a postgres image tag starting with the major version, eg 13 or 13.4-alpine", value)
+	}
+	return nil
+}
+
+func validateLightSyncMode(value string) error {
+	if value != "light" && value != "lightest" {
+		return fmt.Errorf("%q is not light or lightest", value)
+	}
+	return nil
+}
+
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("%q is not true or false", value)
+	}
+	return nil
+}
+
+func validatePort(value string) error {
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%q is not a port between 1 and 65535", value)
+	}
+	return nil
+}
+
+func validateIP(value string) error {
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("%q is not an ip address", value)
+	}
+	return nil
+}
+
+// validateProxyExternal checks the external endpoint of a proxy, `<ip>` or `<ip>:<port>`
+func validateProxyExternal(value string) error {
+	ip, port, err := splitProxyExternal(value)
+	if err != nil || validateIP(ip) != nil || validatePort(port) != nil {
+		return fmt.Errorf("%q is not <ip> or <ip>:<port>", value)
+	}
+	return nil
+}
+
+func validateFile(value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return fmt.Errorf("%q does not exist", value)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%q is a directory", value)
+	}
+	return nil
+}
+
+func validateTomlFile(value string) error {
+	if err := validateFile(value); err != nil {
+		return err
+	}
+	var config map[string]interface{}
+	if _, err := toml.DecodeFile(value, &config); err != nil {
+		return fmt.Errorf("%q is not valid toml: %s", value, err)
+	}
+	return nil
+}
+
+func validateAPIs(value string) error {
+	for _, api := range strings.Split(value, ",") {
+		if !rpcAPIs[strings.TrimSpace(api)] {
+			return fmt.Errorf("%q is not an api, must be one of admin, debug, eth, istanbul, les, miner, net, personal, txpool, web3", api)
+		}
+	}
+	return nil
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not a http(s) url", value)
+	}
+	return nil
+}
+
+// validateNodeID checks the 128 hex characters public key of an enode
+func validateNodeID(value string) error {
+	if !nodeIDRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not an enode id of 128 hex characters", value)
+	}
+	return nil
+}
+
+// validateEnodeURLs checks a comma separated list of `enode://<id>@<ip>:<port>`
+func validateEnodeURLs(value string) error {
+	for _, enode := range strings.Split(value, ",") {
+		u, err := url.Parse(strings.TrimSpace(enode))
+		if err != nil || u.Scheme != "enode" || u.User == nil {
+			return fmt.Errorf("%q is not an enode url", enode)
+		}
+		if err := validateNodeID(u.User.Username()); err != nil {
+			return err
+		}
+		if err := validateIP(u.Hostname()); err != nil {
+			return err
+		}
+		if err := validatePort(u.Port()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAddress checks an address, mixed case addresses must have a valid EIP-55 checksum
+func validateAddress(value string) error {
+	if !addressRegexp.MatchString(value) {
+		return fmt.Errorf("%q is not an address of 0x and 40 hex characters", value)
+	}
+
+	hexAddress := value[2:]
+	if hexAddress == strings.ToLower(hexAddress) || hexAddress == strings.ToUpper(hexAddress) {
+		return nil
+	}
+
+	if checksum := checksumAddress(value); checksum != value {
+		return fmt.Errorf("%q has an invalid checksum, expected %s", value, checksum)
+	}
+	return nil
+}
+
+// checksumAddress returns the EIP-55 mixed case checksum encoding of an address
+func checksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(address, "0x"))
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	result := []byte(lower)
+	for i := range result {
+		if result[i] >= 'a' && hash[i] >= '8' {
+			result[i] -= 'a' - 'A'
+		}
+	}
+
+	return "0x" + string(result)
+}
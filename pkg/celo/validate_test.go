@@ -0,0 +1,115 @@
+package celo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/node"
+)
+
+func TestChecksumAddress(t *testing.T) {
+	// the test vectors of EIP-55
+	tests := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+	}
+
+	for _, want := range tests {
+		t.Run(want, func(t *testing.T) {
+			for _, in := range []string{want, "0x" + strings.ToLower(want[2:]), "0x" + strings.ToUpper(want[2:]), want[2:]} {
+				if got := checksumAddress(in); got != want {
+					t.Errorf("checksumAddress(%s) = %s, want %s", in, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{"checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", false},
+		{"lower case", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", false},
+		{"upper case", "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", false},
+		{"invalid checksum", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", true},
+		{"without 0x", "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"too short", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", true},
+		{"not hex", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beazz", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAddress(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("validateAddress(%s) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParameterProblems(t *testing.T) {
+	const bootnodes = "enode://5aaf10664b12431c250597e980aacd7d5373cae00f128be5b00364344bb96bce7555b50973664bddebd1cb7a6d3fb927bec81527f80e22a26fa373c375fcdefc@35.247.75.229:30301"
+
+	tests := []struct {
+		name       string
+		parameters map[string]string
+		want       []string
+	}{
+		{"networkid unset", map[string]string{"bootnodes": bootnodes}, nil},
+		{"networkid set", map[string]string{"bootnodes": bootnodes, "networkid": "42220"}, nil},
+		{"networkid not a number", map[string]string{"bootnodes": bootnodes, "networkid": "mainnet"}, []string{`networkid: "mainnet" is not a number`}},
+		{"mandatory without default", map[string]string{}, []string{"bootnodes: is mandatory"}},
+		{"invalid value", map[string]string{"bootnodes": bootnodes, "rpcport": "0"}, []string{`rpcport: "0" is not a port between 1 and 65535`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]string{"subtype": "light", "network": "mainnet"}
+			for name, value := range tt.parameters {
+				parameters[name] = value
+			}
+			c := &Celo{n: node.Node{StrParameters: parameters}, Subtype: "light", kind: lookupSubtype("light"), networkID: "42220"}
+
+			if got := c.parameterProblems(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parameterProblems() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNetworkID(t *testing.T) {
+	tests := []struct {
+		name       string
+		builtin    bool
+		parameters map[string]string
+		wantErr    bool
+	}{
+		{"default", true, map[string]string{}, false},
+		{"same as the network", true, map[string]string{"networkid": "42220"}, false},
+		{"differs from a built-in network", true, map[string]string{"networkid": "40120"}, true},
+		{"differs with a genesis file", true, map[string]string{"networkid": "40120", "genesis-file": "/etc/celo/genesis.json"}, false},
+		{"differs from a network of a network file", false, map[string]string{"networkid": "40120"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameters := map[string]string{"subtype": "fullnode", "network": "mainnet"}
+			for name, value := range tt.parameters {
+				parameters[name] = value
+			}
+			c := &Celo{n: node.Node{StrParameters: parameters}, Subtype: "fullnode", kind: lookupSubtype("fullnode"), networkID: "42220", builtinNetwork: tt.builtin}
+			if err := c.decodeConfig(); err != nil {
+				t.Fatalf("decodeConfig() error = %v", err)
+			}
+
+			if err := c.validateNetworkID(); (err != nil) != tt.wantErr {
+				t.Errorf("validateNetworkID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.blockdaemon.com/bpm/sdk/pkg/node"
+)
+
+// attestationStatus the part of the `/status` response of the attestation service that is checked
+type attestationStatus struct {
+	Status         string `json:"status"`
+	AccountAddress string `json:"accountAddress"`
+	Signer         string `json:"signer"`
+}
+
+// runAttestationTests checks that the attestation service is healthy and serves the addresses of node.json
+func runAttestationTests(tr *testRunner, currentNode node.Node, containerName string, port string) error {
+
+	endpoint, err := getContainerEndpoint("/"+containerName, port)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Attestation service %s at %s\n", containerName, endpoint)
+
+	var status attestationStatus
+	testCase := func() (string, string, error) {
+		title := "Status"
+		if err := getJSON(endpoint+"/status", &status); err != nil {
+			return title, "false", err
+		}
+		if status.Status != "ok" {
+			return title, status.Status, fmt.Errorf("attestation service status is %q", status.Status)
+		}
+		return title, status.Status, nil
+	}
+	if err := tr.test(testCase); err != nil {
+		return err
+	}
+
+	testCase = func() (string, string, error) {
+		title := "Validator address"
+		return title, status.AccountAddress, sameAddress("validator", currentNode.StrParameters["validator"], status.AccountAddress)
+	}
+	if err := tr.test(testCase); err != nil {
+		return err
+	}
+
+	testCase = func() (string, string, error) {
+		title := "Signer address"
+		if status.Signer == "" {
+			return title, "false", fmt.Errorf("/status does not report the signer")
+		}
+		return title, status.Signer, sameAddress("signer", currentNode.StrParameters["signer"], status.Signer)
+	}
+	if err := tr.test(testCase); err != nil {
+		return err
+	}
+
+	testCase = func() (string, string, error) {
+		title := "Health"
+		resp, err := http.Get(endpoint + "/healthz")
+		if err != nil {
+			return title, "false", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return title, "false", fmt.Errorf("/healthz returned %s", resp.Status)
+		}
+		return title, "true", nil
+	}
+
+	return tr.test(testCase)
+}
+
+func getJSON(url string, v interface{}) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// sameAddress compares addresses regardless of their checksum case
+func sameAddress(name string, expected string, actual string) error {
+	if !strings.EqualFold(strings.TrimPrefix(expected, "0x"), strings.TrimPrefix(actual, "0x")) {
+		return fmt.Errorf("attestation service runs with %s %s, node.json has %s", name, actual, expected)
+	}
+	return nil
+}
@@ -16,13 +16,17 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-connections/nat"
 	"go.blockdaemon.com/bpm/sdk/pkg/docker"
 	"go.blockdaemon.com/bpm/sdk/pkg/node"
 )
 
 // CeloTester Interface for running tests against node
 type CeloTester struct {
-	Cli *client.Client
+	Cli             *client.Client
+	Container       string // the container to test, `bpm-<id>-<subtype>` if empty
+	RPC             bool   // wether the container serves json rpc
+	AttestationPort string // the container port of the attestation service, empty for nodes
 }
 
 func New() *CeloTester {
@@ -34,7 +38,7 @@ func New() *CeloTester {
 // Test Method for calling tests against node
 func (d CeloTester) Test(currentNode node.Node) (bool, error) {
 
-	results, err := runAllTests(currentNode)
+	results, err := d.runAllTests(currentNode)
 	if err != nil {
 		return false, err
 	}
@@ -77,11 +81,14 @@ func (t *testRunner) test(testFunc func() (title string, result string, err erro
 	return err
 }
 
-func runAllTests(currentNode node.Node) (testRunner, error) {
+func (d CeloTester) runAllTests(currentNode node.Node) (testRunner, error) {
 
 	tr := testRunner{}
 
-	containerName := "bpm-" + currentNode.ID + "-" + currentNode.StrParameters["subtype"]
+	containerName := d.Container
+	if containerName == "" {
+		containerName = "bpm-" + currentNode.ID + "-" + currentNode.StrParameters["subtype"]
+	}
 	fmt.Printf("testing container: %s\n", containerName)
 
 	bm, err := docker.NewBasicManager(currentNode)
@@ -105,6 +112,11 @@ func runAllTests(currentNode node.Node) (testRunner, error) {
 		return tr, err
 	}
 
+	// the attestation service runs no geth to count peers of or call json rpc on
+	if d.AttestationPort != "" {
+		return tr, runAttestationTests(&tr, currentNode, containerName, d.AttestationPort)
+	}
+
 	// test peer count
 	testCase = func() (string, string, error) {
 		title := "Peer Count"
@@ -123,11 +135,11 @@ func runAllTests(currentNode node.Node) (testRunner, error) {
 	testCase = func() (string, string, error) {
 		title := "JSON RPC"
 
-		if currentNode.StrParameters["subtype"] == "validator" {
+		if !d.RPC {
 			return title, "false", nil
 		}
 
-		rpcEndpoint, err := getContainerEndpoint("/" + containerName)
+		rpcEndpoint, err := getContainerEndpoint("/"+containerName, "8545")
 		if err != nil {
 			return title, "false", err
 		}
@@ -203,7 +215,7 @@ func InspectExecResp(ctx context.Context, id string) (ExecResult, error) {
 	}
 	defer docker.Close()
 
-	resp, err := docker.ContainerExecAttach(ctx, id, types.ExecConfig{})
+	resp, err := docker.ContainerExecAttach(ctx, id, types.ExecStartCheck{})
 	if err != nil {
 		return execResult, err
 	}
@@ -279,7 +291,9 @@ func rpcPost(method string, params string, baseUrl string) (int, int, map[string
 	return resp.StatusCode, messageID, data, err
 }
 
-func getContainerEndpoint(name string) (string, error) {
+// getContainerEndpoint returns the url of a container port, on the host if published or else
+// on the docker network by container name
+func getContainerEndpoint(name string, port string) (string, error) {
 
 	cli, err := client.NewEnvClient()
 	if err != nil {
@@ -293,12 +307,13 @@ func getContainerEndpoint(name string) (string, error) {
 		return "", err
 	}
 
-	if len(containerJSON.NetworkSettings.NetworkSettingsBase.Ports["8545/tcp"]) == 0 {
-		hostPort = "8545"
+	bindings := containerJSON.NetworkSettings.NetworkSettingsBase.Ports[nat.Port(port+"/tcp")]
+	if len(bindings) == 0 {
+		hostPort = port
 		host = strings.Replace(name, "/", "", -1)
-	} else if len(containerJSON.NetworkSettings.NetworkSettingsBase.Ports["8545/tcp"]) > 0 {
-		hostPort = containerJSON.NetworkSettings.NetworkSettingsBase.Ports["8545/tcp"][0].HostPort
-		host = containerJSON.NetworkSettings.NetworkSettingsBase.Ports["8545/tcp"][0].HostIP
+	} else if len(bindings) > 0 {
+		hostPort = bindings[0].HostPort
+		host = bindings[0].HostIP
 	} else {
 		return "", errors.New("No rpc port set")
 	}
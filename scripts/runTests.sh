@@ -30,14 +30,12 @@ function main() {
 
     for subtype in "${NODES[@]}"; do
 
-        if [ "$subtype" != "attestation-service" ]; then
-            echo ""
-            echo "Testing $subtype"
-            cd $PROJECT_ROOT/build/$subtype
-            ./$BINARY test node.$subtype.json
-            echo "finished ${subtype}!"
-            echo ""
-        fi
+        echo ""
+        echo "Testing $subtype"
+        cd $PROJECT_ROOT/build/$subtype
+        ./$BINARY test node.$subtype.json
+        echo "finished ${subtype}!"
+        echo ""
     done
 }
 
@@ -56,18 +54,8 @@ function setupWorkspace() {
 function setupValidator() {
 
     echo "updating validator json with proxy details..."
-    proxy=bpm-celo-test-proxy
-
-    # get enode url, ip
-    enode=$(docker exec ${proxy} geth --exec "admin.nodeInfo['enode'].split('//')[1].split('@')[0]" attach | tr -d '"')
-    proxyIP=$(docker inspect -f '{{range .NetworkSettings.Networks}}{{.IPAddress}}{{end}}' ${proxy})
     externalIP=$(dig +short myip.opendns.com @resolver1.opendns.com)
-    echo "proxy enode: ${enode}"
-    echo "proxy ip: ${proxyIP}"
-
-    cat <<< $(jq --arg enode "$enode" '.str_parameters.enode = $enode' node.validator.json) > node.validator.json
-    cat <<< $(jq --arg externalIP "$externalIP" '.str_parameters.proxy_external = $externalIP' node.validator.json) > node.validator.json
-    cat <<< $(jq --arg proxyIP "$proxyIP" '.str_parameters.proxy_internal = $proxyIP' node.validator.json) > node.validator.json
+    ./$BINARY link-proxy $PROJECT_ROOT/build/proxy/node.proxy.json node.validator.json $externalIP
 
     echo "... done updating validator json"
 }